@@ -5,33 +5,53 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"bypirob/airo/src/internal/config"
 	"bypirob/airo/src/internal/docker"
 )
 
 var (
 	buildTag     string
 	buildContext string
+	buildNoCache bool
+	buildPull    bool
 )
 
 var buildCmd = &cobra.Command{
-	Use:   "build",
+	Use:   "build [image|container...]",
 	Short: "Build a Docker image",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg, err := loadConfig()
+		cfg, err := loadSelectedConfig(args)
 		if err != nil {
 			return err
 		}
 
-		if err := docker.BuildImage(cfg, projectPath, buildTag, buildContext); err != nil {
-			return fmt.Errorf("build failed: %w", err)
+		if buildTag == "" {
+			defaultTag, err := docker.DefaultTagSuffix(cfg, projectPath)
+			if err != nil {
+				return err
+			}
+			buildTag = defaultTag
 		}
 
-		return nil
+		err = runWithHooks(cfg, buildTag, config.HookPreBuild, config.HookPostBuild, func() error {
+			if docker.PushesOnBuild(cfg) {
+				if err := docker.RunHooks(cfg, projectPath, config.HookPrePush, buildTag, nil); err != nil {
+					return err
+				}
+			}
+			if err := docker.BuildImage(cfg, projectPath, buildTag, buildContext, docker.BuildOptions{NoCache: buildNoCache, Pull: buildPull}); err != nil {
+				return fmt.Errorf("build failed: %w", err)
+			}
+			return nil
+		})
+		return runFailureHooks(cmd, cfg, buildTag, err)
 	},
 }
 
 func init() {
-	buildCmd.Flags().StringVar(&buildTag, "tag", "", "image tag suffix (default: <yyyymmdd-hhmm>-<shortsha>)")
+	buildCmd.Flags().StringVar(&buildTag, "tag", "", "image tag suffix (default: from tag_strategy)")
 	buildCmd.Flags().StringVar(&buildContext, "context", ".", "build context path")
+	buildCmd.Flags().BoolVar(&buildNoCache, "no-cache", false, "do not use cache when building images")
+	buildCmd.Flags().BoolVar(&buildPull, "pull", false, "always pull newer versions of base images")
 	rootCmd.AddCommand(buildCmd)
 }
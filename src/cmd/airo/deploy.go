@@ -5,16 +5,17 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"bypirob/airo/src/internal/config"
 	"bypirob/airo/src/internal/docker"
 )
 
 var deployTag string
 
 var deployCmd = &cobra.Command{
-	Use:   "deploy",
+	Use:   "deploy [image|container...]",
 	Short: "Deploy a Docker image over SSH",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg, err := loadConfig()
+		cfg, err := loadSelectedConfig(args)
 		if err != nil {
 			return err
 		}
@@ -25,11 +26,17 @@ var deployCmd = &cobra.Command{
 			return fmt.Errorf("--tag is required")
 		}
 
-		if err := docker.Deploy(cfg, deployTag); err != nil {
-			return fmt.Errorf("deploy failed: %w", err)
-		}
-
-		return nil
+		err = withLock(cfg, deployTag, func() error {
+			return withHistory(cfg, "deploy", deployTag, func() error {
+				return runWithHooks(cfg, deployTag, config.HookPreDeploy, config.HookPostDeploy, func() error {
+					if err := docker.Deploy(cfg, projectPath, deployTag); err != nil {
+						return fmt.Errorf("deploy failed: %w", err)
+					}
+					return nil
+				})
+			})
+		})
+		return runFailureHooks(cmd, cfg, deployTag, err)
 	},
 }
 
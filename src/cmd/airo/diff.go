@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"bypirob/airo/src/internal/docker"
+)
+
+var diffCmd = &cobra.Command{
+	Use:   "diff",
+	Short: "Show commits not yet deployed to each container",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		if cfg.Deploy.Type != "ssh" {
+			return fmt.Errorf("deploy.type must be ssh for diff")
+		}
+
+		details, err := docker.ContainerDetails(cfg)
+		if err != nil {
+			return err
+		}
+
+		for _, info := range details {
+			revision := info.Labels[docker.LabelRevision]
+			switch {
+			case info.Image == "":
+				cmd.Printf("%s: not deployed\n", info.Name)
+				continue
+			case revision == "":
+				cmd.Printf("%s: %s has no revision label\n", info.Name, info.Image)
+				continue
+			case !docker.IsCommitSHA(revision):
+				cmd.Printf("%s: %s has an invalid revision label %q\n", info.Name, info.Image, revision)
+				continue
+			}
+
+			label := fmt.Sprintf("%s: %s (%s", info.Name, info.Image, shortRevision(revision))
+			if info.Labels["airo.git.dirty"] == "true" {
+				label += ", built from a dirty tree"
+			}
+			label += ")"
+
+			commits, err := docker.CommitsSince(projectPath, revision)
+			if err != nil {
+				cmd.Printf("%s, revision not found locally\n", label)
+				continue
+			}
+			if len(commits) == 0 {
+				cmd.Printf("%s is up to date\n", label)
+				continue
+			}
+			cmd.Printf("%s is %d commits behind HEAD\n", label, len(commits))
+			for _, commit := range commits {
+				cmd.Printf("  %s\n", commit)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"bypirob/airo/src/internal/docker"
+)
+
+var (
+	dockerfileWrite bool
+	dockerfileForce bool
+)
+
+var dockerfileCmd = &cobra.Command{
+	Use:   "dockerfile [image]",
+	Short: "Print the Dockerfile airo generates when the project has none",
+	Args:  cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		names := cfg.ImageNames()
+		name := names[0]
+		if len(args) == 1 {
+			name = args[0]
+			if _, ok := cfg.Images[name]; !ok {
+				return fmt.Errorf("unknown image %q", name)
+			}
+		} else if len(names) > 1 {
+			return fmt.Errorf("choose an image: %v", names)
+		}
+
+		stack, content, err := docker.GenerateDockerfile(cfg, projectPath, name)
+		if err != nil {
+			return err
+		}
+		if !dockerfileWrite {
+			cmd.Print(content)
+			return nil
+		}
+
+		path := resolveProjectPath("Dockerfile")
+		if docker.HasDockerfile(projectPath) && !dockerfileForce {
+			return fmt.Errorf("%s already exists, use --force to overwrite it", path)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			return fmt.Errorf("write dockerfile (%s): %w", path, err)
+		}
+		cmd.Printf("Wrote %s Dockerfile to %s\n", stack, path)
+		return nil
+	},
+}
+
+func init() {
+	dockerfileCmd.Flags().BoolVar(&dockerfileWrite, "write", false, "write the generated Dockerfile to the project")
+	dockerfileCmd.Flags().BoolVar(&dockerfileForce, "force", false, "overwrite an existing Dockerfile with --write")
+	rootCmd.AddCommand(dockerfileCmd)
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"bypirob/airo/src/internal/docker"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the local tools, the server, and the config before deploying",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		checks := docker.Doctor(cfg, projectPath)
+		failures, warnings := 0, 0
+		for _, check := range checks {
+			switch check.Status {
+			case docker.CheckFail:
+				failures++
+			case docker.CheckWarn:
+				warnings++
+			}
+			cmd.Printf("%-5s %s: %s\n", check.Status, check.Name, check.Detail)
+			if check.Hint != "" {
+				cmd.Printf("      %s\n", check.Hint)
+			}
+		}
+
+		if failures > 0 {
+			return fmt.Errorf("%d of %d checks failed", failures, len(checks))
+		}
+		cmd.Printf("All %d checks passed", len(checks))
+		if warnings > 0 {
+			cmd.Printf(" (%d warnings)", warnings)
+		}
+		cmd.Println()
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
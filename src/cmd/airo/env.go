@@ -0,0 +1,250 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"bypirob/airo/src/internal/config"
+	"bypirob/airo/src/internal/docker"
+	"bypirob/airo/src/internal/envfile"
+)
+
+var (
+	envShowValues bool
+	envRestart    bool
+)
+
+var envCmd = &cobra.Command{
+	Use:   "env",
+	Short: "Manage container env files on the server",
+}
+
+var envListCmd = &cobra.Command{
+	Use:   "list <container>",
+	Short: "List env variables",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, container, err := loadEnvContainer(args[0])
+		if err != nil {
+			return err
+		}
+		file, err := readEnv(cfg, container)
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range file.Entries() {
+			value := entry.Value
+			if !envShowValues {
+				value = envfile.Mask(value)
+			}
+			cmd.Printf("%s=%s\n", entry.Key, value)
+		}
+		return nil
+	},
+}
+
+var envGetCmd = &cobra.Command{
+	Use:   "get <container> <key>",
+	Short: "Print an env variable",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, container, err := loadEnvContainer(args[0])
+		if err != nil {
+			return err
+		}
+		file, err := readEnv(cfg, container)
+		if err != nil {
+			return err
+		}
+
+		value, ok := file.Get(args[1])
+		if !ok {
+			return fmt.Errorf("%s is not set for %s", args[1], container.Name)
+		}
+		cmd.Println(value)
+		return nil
+	},
+}
+
+var envSetCmd = &cobra.Command{
+	Use:   "set <container> <key=value>...",
+	Short: "Set env variables",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, container, err := loadEnvContainer(args[0])
+		if err != nil {
+			return err
+		}
+		file, err := readEnv(cfg, container)
+		if err != nil {
+			return err
+		}
+
+		updated := envfile.Parse(file.String())
+		for _, arg := range args[1:] {
+			key, value, ok := strings.Cut(arg, "=")
+			if !ok || !envfile.ValidKey(key) {
+				return fmt.Errorf("invalid assignment %q, expected KEY=value", arg)
+			}
+			updated.Set(key, value)
+		}
+		return writeEnv(cmd, cfg, container, file, updated)
+	},
+}
+
+var envUnsetCmd = &cobra.Command{
+	Use:   "unset <container> <key>...",
+	Short: "Remove env variables",
+	Args:  cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, container, err := loadEnvContainer(args[0])
+		if err != nil {
+			return err
+		}
+		file, err := readEnv(cfg, container)
+		if err != nil {
+			return err
+		}
+
+		updated := envfile.Parse(file.String())
+		for _, key := range args[1:] {
+			if !updated.Unset(key) {
+				return fmt.Errorf("%s is not set for %s", key, container.Name)
+			}
+		}
+		return writeEnv(cmd, cfg, container, file, updated)
+	},
+}
+
+var envPushCmd = &cobra.Command{
+	Use:   "push <container> [file]",
+	Short: "Upload a local env file to the server",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, container, err := loadEnvContainer(args[0])
+		if err != nil {
+			return err
+		}
+
+		localPath := ".env"
+		if len(args) > 1 {
+			localPath = args[1]
+		}
+		data, err := os.ReadFile(resolveProjectPath(localPath))
+		if err != nil {
+			return fmt.Errorf("read env file: %w", err)
+		}
+
+		file, err := readEnv(cfg, container)
+		if err != nil {
+			return err
+		}
+		return writeEnv(cmd, cfg, container, file, envfile.Parse(string(data)))
+	},
+}
+
+var envPullCmd = &cobra.Command{
+	Use:   "pull <container> [file]",
+	Short: "Download the env file from the server",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, container, err := loadEnvContainer(args[0])
+		if err != nil {
+			return err
+		}
+		contents, err := docker.ReadEnvFile(cfg, container)
+		if err != nil {
+			return err
+		}
+
+		if len(args) == 1 {
+			cmd.Print(contents)
+			return nil
+		}
+		if err := os.WriteFile(resolveProjectPath(args[1]), []byte(contents), 0o600); err != nil {
+			return fmt.Errorf("write env file: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	envListCmd.Flags().BoolVar(&envShowValues, "show-values", false, "print values instead of masking them")
+	for _, cmd := range []*cobra.Command{envSetCmd, envUnsetCmd, envPushCmd} {
+		cmd.Flags().BoolVar(&envRestart, "restart", false, "recreate the container so it picks up the changes")
+	}
+	envCmd.AddCommand(envListCmd, envGetCmd, envSetCmd, envUnsetCmd, envPushCmd, envPullCmd)
+	rootCmd.AddCommand(envCmd)
+}
+
+func loadEnvContainer(name string) (config.Config, config.ContainerConfig, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return config.Config{}, config.ContainerConfig{}, err
+	}
+	if cfg.Deploy.Type != "ssh" {
+		return config.Config{}, config.ContainerConfig{}, fmt.Errorf("deploy.type must be ssh for env")
+	}
+
+	container, ok := cfg.Container(name)
+	if !ok {
+		return config.Config{}, config.ContainerConfig{}, fmt.Errorf("%q is not defined in deploy.containers", name)
+	}
+	if container.EnvFile == "" {
+		return config.Config{}, config.ContainerConfig{}, fmt.Errorf("deploy.containers.env_file is not set for %s", name)
+	}
+	return cfg, container, nil
+}
+
+func readEnv(cfg config.Config, container config.ContainerConfig) (envfile.File, error) {
+	contents, err := docker.ReadEnvFile(cfg, container)
+	if err != nil {
+		return envfile.File{}, err
+	}
+	return envfile.Parse(contents), nil
+}
+
+func writeEnv(cmd *cobra.Command, cfg config.Config, container config.ContainerConfig, before, after envfile.File) error {
+	changes := envfile.Diff(before, after)
+	if len(changes) == 0 {
+		cmd.Println("No changes")
+		return nil
+	}
+
+	values := make(map[string]string)
+	for _, entry := range after.Entries() {
+		values[entry.Key] = entry.Value
+	}
+	for _, change := range changes {
+		switch change.Kind {
+		case envfile.Added:
+			cmd.Printf("+ %s=%s\n", change.Key, envfile.Mask(values[change.Key]))
+		case envfile.Changed:
+			cmd.Printf("~ %s=%s\n", change.Key, envfile.Mask(values[change.Key]))
+		case envfile.Removed:
+			cmd.Printf("- %s\n", change.Key)
+		}
+	}
+
+	if err := docker.WriteEnvFile(cfg, container, after.String()); err != nil {
+		return err
+	}
+	if envRestart {
+		if err := docker.RestartContainer(cfg, container); err != nil {
+			return fmt.Errorf("restart failed: %w", err)
+		}
+	}
+	return nil
+}
+
+func resolveProjectPath(path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(projectPath, path)
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"bypirob/airo/src/internal/config"
+	"bypirob/airo/src/internal/docker"
+)
+
+var (
+	historyContainer string
+	historyLimit     int
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "List deploys recorded on the server",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		if cfg.Deploy.Type != "ssh" {
+			return fmt.Errorf("deploy.type must be ssh for history")
+		}
+
+		records, err := docker.History(cfg, historyContainer)
+		if err != nil {
+			return err
+		}
+		if historyLimit > 0 && len(records) > historyLimit {
+			records = records[len(records)-historyLimit:]
+		}
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TIME\tCOMMAND\tUSER\tGIT\tOUTCOME\tDURATION\tCONTAINERS")
+		for _, record := range records {
+			git := record.GitSHA
+			if record.GitBranch != "" {
+				git = fmt.Sprintf("%s@%s", record.GitBranch, record.GitSHA)
+			}
+			containers := make([]string, 0, len(record.Containers))
+			for _, name := range slices.Sorted(maps.Keys(record.Containers)) {
+				containers = append(containers, fmt.Sprintf("%s=%s", name, record.Containers[name]))
+			}
+			duration := time.Duration(record.Duration * float64(time.Second)).Round(time.Second)
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				record.Time.Local().Format("2006-01-02 15:04:05"),
+				record.Command, record.User, git, record.Outcome, duration, strings.Join(containers, " "))
+		}
+		return w.Flush()
+	},
+}
+
+func init() {
+	historyCmd.Flags().StringVar(&historyContainer, "container", "", "only show deploys of this container")
+	historyCmd.Flags().IntVar(&historyLimit, "limit", 20, "number of records to show (0 for all)")
+	rootCmd.AddCommand(historyCmd)
+}
+
+func withHistory(cfg config.Config, command, tag string, run func() error) error {
+	record, err := docker.NewHistoryRecord(cfg, projectPath, command, tag)
+	if err != nil {
+		return err
+	}
+
+	err = run()
+	record.Finish(err)
+	if historyErr := docker.AppendHistory(cfg, record); historyErr != nil {
+		rootCmd.PrintErrln(historyErr)
+	}
+	return err
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"bypirob/airo/src/internal/config"
+	"bypirob/airo/src/internal/docker"
+)
+
+func runWithHooks(cfg config.Config, tag, pre, post string, step func() error) error {
+	if err := docker.RunHooks(cfg, projectPath, pre, tag, nil); err != nil {
+		return err
+	}
+	if err := step(); err != nil {
+		return err
+	}
+	return docker.RunHooks(cfg, projectPath, post, tag, nil)
+}
+
+func runFailureHooks(cmd *cobra.Command, cfg config.Config, tag string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if hookErr := docker.RunHooks(cfg, projectPath, config.HookOnFailure, tag, err); hookErr != nil {
+		cmd.PrintErrln(hookErr)
+	}
+	return err
+}
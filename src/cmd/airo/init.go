@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"bypirob/airo/src/internal/config"
+	"bypirob/airo/src/internal/docker"
+	"bypirob/airo/src/internal/dockerfile"
+)
+
+var (
+	initNonInteractive bool
+	initForce          bool
+	initSkipChecks     bool
+	initName           string
+	initHost           string
+	initUser           string
+	initIdentityFile   string
+	initSSHPort        int
+	initPort           int
+	initAppPort        int
+)
+
+type initOptions struct {
+	Name         string
+	BaseImage    string
+	TargetArch   string
+	Host         string
+	User         string
+	IdentityFile string
+	SSHPort      int
+	Port         int
+	AppPort      int
+}
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Create airo.yaml for this project",
+	Long: "Create airo.yaml for this project.\n\n" +
+		"init detects the stack and an existing Dockerfile, asks for the server details,\n" +
+		"checks that the server is reachable over ssh and runs Docker, and writes a config\n" +
+		"that passes validation. With --non-interactive, values come from flags and defaults.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := resolveProjectPath(configPath)
+		if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) && !initForce {
+			return fmt.Errorf("%s already exists, use --force to overwrite it", path)
+		}
+
+		opts := initOptions{
+			Name:         initName,
+			Host:         initHost,
+			User:         initUser,
+			IdentityFile: initIdentityFile,
+			SSHPort:      initSSHPort,
+			Port:         initPort,
+			AppPort:      initAppPort,
+			TargetArch:   config.DefaultTargetArch,
+		}
+		if opts.Name == "" {
+			opts.Name = defaultProjectName()
+		}
+		if opts.IdentityFile == "" {
+			opts.IdentityFile = defaultIdentityFile()
+		}
+
+		stack, stackErr := dockerfile.Detect(projectPath)
+		switch {
+		case docker.HasDockerfile(projectPath):
+			cmd.Println("Found Dockerfile")
+		case stackErr == nil:
+			cmd.Printf("No Dockerfile, airo will generate one for this %s project\n", stack)
+		default:
+			cmd.Printf("Warning: no Dockerfile and no known stack detected, add a Dockerfile before building\n")
+		}
+		if stackErr == nil {
+			if opts.AppPort == 0 {
+				opts.AppPort = stack.DefaultPort()
+			}
+			if !docker.HasDockerfile(projectPath) {
+				opts.BaseImage = stack.DefaultImage()
+			}
+		}
+		if opts.AppPort == 0 {
+			opts.AppPort = 3000
+		}
+
+		if !initNonInteractive {
+			var err error
+			p := &prompter{reader: bufio.NewReader(cmd.InOrStdin()), out: cmd.OutOrStdout()}
+			opts.Name = p.ask("Image name", opts.Name)
+			opts.Host = p.ask("SSH host", opts.Host)
+			opts.User = p.ask("SSH user", opts.User)
+			opts.IdentityFile = p.ask("SSH key", opts.IdentityFile)
+			if opts.SSHPort, err = p.askInt("SSH port", opts.SSHPort); err != nil {
+				return err
+			}
+			if opts.AppPort, err = p.askInt("App port (inside the container)", opts.AppPort); err != nil {
+				return err
+			}
+			if opts.Port == 0 {
+				opts.Port = opts.AppPort
+			}
+			if opts.Port, err = p.askInt("Host port", opts.Port); err != nil {
+				return err
+			}
+		}
+		if opts.Port == 0 {
+			opts.Port = opts.AppPort
+		}
+		if opts.Host == "" {
+			return fmt.Errorf("ssh host is required, pass --host")
+		}
+
+		content := initConfig(opts)
+		cfg, err := config.Parse([]byte(content))
+		if err != nil {
+			return fmt.Errorf("generated config is invalid: %w", err)
+		}
+
+		if !initSkipChecks {
+			if err := docker.CheckSSH(cfg); err != nil {
+				cmd.Printf("Warning: %v\n", err)
+				cmd.Println("  Check the host, user, port and key, or add the key to your ssh agent.")
+			} else if version, err := docker.ServerDockerVersion(cfg); err != nil {
+				cmd.Printf("Warning: %v\n", err)
+				cmd.Println("  Run `airo server setup` to install Docker and give the user access to it.")
+			} else {
+				cmd.Printf("Connected to %s, Docker %s\n", opts.Host, version)
+				if platform, err := docker.RemotePlatform(cfg); err == nil && platform != opts.TargetArch {
+					opts.TargetArch = platform
+					content = initConfig(opts)
+				}
+			}
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			return fmt.Errorf("write config (%s): %w", path, err)
+		}
+		cmd.Printf("Wrote %s\n", path)
+		cmd.Println("Next: airo release")
+		return nil
+	},
+}
+
+type prompter struct {
+	reader *bufio.Reader
+	out    io.Writer
+	eof    bool
+}
+
+func (p *prompter) ask(label, value string) string {
+	if value != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", label, value)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", label)
+	}
+	answer, err := p.reader.ReadString('\n')
+	if err != nil {
+		p.eof = true
+	}
+	if answer = strings.TrimSpace(answer); answer != "" {
+		return answer
+	}
+	return value
+}
+
+func (p *prompter) askInt(label string, value int) (int, error) {
+	for {
+		answer := p.ask(label, strconv.Itoa(value))
+		number, err := strconv.Atoi(answer)
+		if err == nil && number > 0 && number < 65536 {
+			return number, nil
+		}
+		if p.eof {
+			return 0, fmt.Errorf("%s: %q is not a valid port", strings.ToLower(label), answer)
+		}
+		fmt.Fprintf(p.out, "%q is not a valid port\n", answer)
+	}
+}
+
+func initConfig(opts initOptions) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "images:\n  %s:\n", opts.Name)
+	if opts.BaseImage != "" {
+		fmt.Fprintf(&b, "    base_image: %s\n", strconv.Quote(opts.BaseImage))
+	}
+	fmt.Fprintf(&b, "    target_arch: %s\n", opts.TargetArch)
+	fmt.Fprintf(&b, "deploy:\n  type: ssh\n  containers:\n")
+	fmt.Fprintf(&b, "    - name: %s\n      image: %s\n", strconv.Quote(opts.Name), strconv.Quote(opts.Name))
+	fmt.Fprintf(&b, "      port: %d\n      app_port: %d\n", opts.Port, opts.AppPort)
+	fmt.Fprintf(&b, "  ssh:\n    host: %s\n", strconv.Quote(opts.Host))
+	if opts.User != "" {
+		fmt.Fprintf(&b, "    user: %s\n", strconv.Quote(opts.User))
+	}
+	fmt.Fprintf(&b, "    port: %d\n", opts.SSHPort)
+	if opts.IdentityFile != "" {
+		fmt.Fprintf(&b, "    identity_file: %s\n", strconv.Quote(opts.IdentityFile))
+	}
+	return b.String()
+}
+
+func defaultProjectName() string {
+	path, err := filepath.Abs(projectPath)
+	if err != nil {
+		return "app"
+	}
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		default:
+			return '-'
+		}
+	}, filepath.Base(path))
+	if name = strings.Trim(name, "-_"); name == "" {
+		return "app"
+	}
+	return name
+}
+
+func defaultIdentityFile() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	for _, name := range []string{"id_ed25519", "id_ecdsa", "id_rsa"} {
+		if _, err := os.Stat(filepath.Join(home, ".ssh", name)); err == nil {
+			return "~/.ssh/" + name
+		}
+	}
+	return ""
+}
+
+func init() {
+	initCmd.Flags().BoolVar(&initNonInteractive, "non-interactive", false, "do not prompt, use flags and defaults")
+	initCmd.Flags().BoolVar(&initForce, "force", false, "overwrite an existing config")
+	initCmd.Flags().BoolVar(&initSkipChecks, "skip-checks", false, "do not connect to the server")
+	initCmd.Flags().StringVar(&initName, "name", "", "image and container name (default: project directory name)")
+	initCmd.Flags().StringVar(&initHost, "host", "", "ssh host of the server")
+	initCmd.Flags().StringVar(&initUser, "user", "root", "ssh user")
+	initCmd.Flags().StringVar(&initIdentityFile, "identity-file", "", "ssh private key (default: ~/.ssh/id_ed25519, id_ecdsa or id_rsa)")
+	initCmd.Flags().IntVar(&initSSHPort, "ssh-port", 22, "ssh port")
+	initCmd.Flags().IntVar(&initPort, "port", 0, "port published on the server (default: app port)")
+	initCmd.Flags().IntVar(&initAppPort, "app-port", 0, "port the app listens on in the container (default: from the detected stack)")
+	rootCmd.AddCommand(initCmd)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"bypirob/airo/src/internal/config"
+	"bypirob/airo/src/internal/docker"
+)
+
+var (
+	promoteFrom string
+	promoteTo   string
+	promoteOnly []string
+)
+
+var promoteCmd = &cobra.Command{
+	Use:   "promote <tag>",
+	Short: "Copy a tested image between environments and deploy it",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if promoteFrom == promoteTo {
+			return fmt.Errorf("--from and --to must be different environments")
+		}
+
+		base, err := config.Load(projectPath, configPath)
+		if err != nil {
+			return err
+		}
+		base, err = config.Select(base, promoteOnly)
+		if err != nil {
+			return err
+		}
+		from, err := config.ForEnvironment(base, promoteFrom)
+		if err != nil {
+			return err
+		}
+		to, err := config.ForEnvironment(base, promoteTo)
+		if err != nil {
+			return err
+		}
+
+		tag := args[0]
+		promote := func() error {
+			if err := docker.PromoteImage(from, to, tag); err != nil {
+				return fmt.Errorf("promote failed: %w", err)
+			}
+			if to.Deploy.Type != "ssh" {
+				cmd.Printf("Promoted %s to %s; deploy is only available for ssh environments\n", tag, promoteTo)
+				return nil
+			}
+			return runWithHooks(to, tag, config.HookPreDeploy, config.HookPostDeploy, func() error {
+				if err := docker.Deploy(to, projectPath, tag); err != nil {
+					return fmt.Errorf("deploy failed: %w", err)
+				}
+				return nil
+			})
+		}
+
+		if to.Deploy.Type == "ssh" {
+			err = withLock(to, tag, func() error {
+				return withHistory(to, "promote", tag, promote)
+			})
+		} else {
+			err = promote()
+		}
+		return runFailureHooks(cmd, to, tag, err)
+	},
+}
+
+func init() {
+	promoteCmd.Flags().StringVar(&promoteFrom, "from", "", "environment that has the tested image")
+	promoteCmd.Flags().StringVar(&promoteTo, "to", "", "environment to copy the image to and deploy")
+	promoteCmd.Flags().StringSliceVar(&promoteOnly, "only", nil, "limit the promotion to these images or containers")
+	_ = promoteCmd.MarkFlagRequired("from")
+	_ = promoteCmd.MarkFlagRequired("to")
+	rootCmd.AddCommand(promoteCmd)
+}
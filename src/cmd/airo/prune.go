@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"bypirob/airo/src/internal/docker"
+)
+
+var (
+	pruneDryRun        bool
+	pruneYes           bool
+	pruneResources     bool
+	pruneVolumes       bool
+	pruneLocal         bool
+	pruneHost          bool
+	pruneRegistry      bool
+	pruneKeepLast      int
+	pruneKeepNewerThan string
+)
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove old images and unused networks and volumes",
+	Long: "Remove old images and unused networks and volumes.\n\n" +
+		"Without target flags, prune cleans local images, and for ssh deploys also server images\n" +
+		"and networks; registry tags are pruned with --registry. Volumes hold data and are only\n" +
+		"removed with --volumes.\n" +
+		"The deployed tag of each container and the tag before it are always kept.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		opts := docker.PruneOptions{
+			Resources: pruneResources,
+			Volumes:   pruneVolumes,
+			Local:     pruneLocal,
+			Host:      pruneHost,
+			Registry:  pruneRegistry,
+			KeepLast:  *cfg.Retention.KeepLast,
+		}
+		if !opts.Resources && !opts.Volumes && !opts.Local && !opts.Host && !opts.Registry {
+			opts.Local = true
+			opts.Resources = cfg.Deploy.Type == "ssh"
+			opts.Host = cfg.Deploy.Type == "ssh"
+		}
+		if (opts.Resources || opts.Volumes || opts.Host) && cfg.Deploy.Type != "ssh" {
+			return fmt.Errorf("deploy.type must be ssh to prune the server")
+		}
+		if cmd.Flags().Changed("keep-last") {
+			opts.KeepLast = pruneKeepLast
+		}
+		keepNewerThan := cfg.Retention.KeepNewerThan
+		if pruneKeepNewerThan != "" {
+			keepNewerThan = pruneKeepNewerThan
+		}
+		if keepNewerThan != "" {
+			opts.KeepNewerThan, err = time.ParseDuration(keepNewerThan)
+			if err != nil {
+				return fmt.Errorf("invalid --keep-newer-than: %w", err)
+			}
+		}
+
+		candidates, err := docker.PlanPrune(cfg, opts)
+		if err != nil {
+			return err
+		}
+		if len(candidates) == 0 {
+			cmd.Println("Nothing to prune")
+			return nil
+		}
+
+		for _, candidate := range candidates {
+			cmd.Printf("%s %s\n", candidate.Kind, candidate.Name)
+		}
+		if pruneDryRun {
+			return nil
+		}
+		if !pruneYes {
+			cmd.Printf("Remove %d items? [y/N] ", len(candidates))
+			answer, _ := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+			if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+				return fmt.Errorf("prune cancelled")
+			}
+		}
+
+		failed := 0
+		for _, candidate := range candidates {
+			if err := candidate.Remove(); err != nil {
+				failed++
+				cmd.PrintErrf("Failed to remove %s %s: %v\n", candidate.Kind, candidate.Name, err)
+				continue
+			}
+			cmd.Printf("Removed %s %s\n", candidate.Kind, candidate.Name)
+		}
+		if failed > 0 {
+			return fmt.Errorf("prune failed for %d items", failed)
+		}
+
+		return nil
+	},
+}
+
+func init() {
+	pruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "list what would be removed without removing it")
+	pruneCmd.Flags().BoolVarP(&pruneYes, "yes", "y", false, "remove without asking for confirmation")
+	pruneCmd.Flags().BoolVar(&pruneResources, "resources", false, "prune unused networks on the server")
+	pruneCmd.Flags().BoolVar(&pruneVolumes, "volumes", false, "prune unused airo-managed volumes on the server, deleting their data")
+	pruneCmd.Flags().BoolVar(&pruneLocal, "local", false, "prune local images")
+	pruneCmd.Flags().BoolVar(&pruneHost, "host", false, "prune images on the server")
+	pruneCmd.Flags().BoolVar(&pruneRegistry, "registry", false, "prune registry tags")
+	pruneCmd.Flags().IntVar(&pruneKeepLast, "keep-last", 0, "number of tags to keep per image (default: retention.keep_last)")
+	pruneCmd.Flags().StringVar(&pruneKeepNewerThan, "keep-newer-than", "", "keep tags newer than this duration (default: retention.keep_newer_than)")
+	rootCmd.AddCommand(pruneCmd)
+}
@@ -5,25 +5,28 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"bypirob/airo/src/internal/config"
 	"bypirob/airo/src/internal/docker"
 )
 
 var pushCmd = &cobra.Command{
-	Use:   "push <tag>",
+	Use:   "push <tag> [image|container...]",
 	Short: "Push a Docker image",
-	Args:  cobra.ExactArgs(1),
+	Args:  cobra.MinimumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg, err := loadConfig()
+		cfg, err := loadSelectedConfig(args[1:])
 		if err != nil {
 			return err
 		}
 
 		tag := args[0]
-		if err := docker.PushImage(cfg, projectPath, tag); err != nil {
-			return fmt.Errorf("push failed: %w", err)
-		}
-
-		return nil
+		err = runWithHooks(cfg, tag, config.HookPrePush, "", func() error {
+			if err := docker.PushImage(cfg, projectPath, tag); err != nil {
+				return fmt.Errorf("push failed: %w", err)
+			}
+			return nil
+		})
+		return runFailureHooks(cmd, cfg, tag, err)
 	},
 }
 
@@ -2,22 +2,28 @@ package main
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 
+	"bypirob/airo/src/internal/config"
 	"bypirob/airo/src/internal/docker"
 )
 
 var (
 	releaseTag     string
 	releaseContext string
+	releaseOnly    []string
+	releaseDirty   bool
+	releaseNoCache bool
+	releasePull    bool
 )
 
 var releaseCmd = &cobra.Command{
 	Use:   "release",
 	Short: "Build, push, and deploy",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg, err := loadConfig()
+		cfg, err := loadSelectedConfig(releaseOnly)
 		if err != nil {
 			return err
 		}
@@ -25,29 +31,94 @@ var releaseCmd = &cobra.Command{
 			return fmt.Errorf("deploy.type must be ssh for release")
 		}
 		if releaseTag == "" {
-			defaultTag, err := docker.DefaultTagSuffix(projectPath)
+			defaultTag, err := docker.DefaultTagSuffix(cfg, projectPath)
 			if err != nil {
 				return err
 			}
 			releaseTag = defaultTag
 		}
+		if err := checkGitState(cmd, cfg); err != nil {
+			return err
+		}
+
+		err = withHistory(cfg, "release", releaseTag, func() error {
+			return release(cmd, cfg)
+		})
+		return runFailureHooks(cmd, cfg, releaseTag, err)
+	},
+}
+
+func checkGitState(cmd *cobra.Command, cfg config.Config) error {
+	state, err := docker.ReadGitState(projectPath)
+	if err != nil {
+		return nil
+	}
+	problems := state.Problems()
+	if len(problems) == 0 {
+		return nil
+	}
+
+	policy := cfg.DirtyPolicy
+	if releaseDirty {
+		policy = config.DirtyRefuse
+	}
+	switch policy {
+	case config.DirtyRefuse:
+		return fmt.Errorf("%s; commit and push before releasing", strings.Join(problems, "; "))
+	case config.DirtySuffix:
+		releaseTag += state.TagSuffix()
+	}
+	for _, problem := range problems {
+		cmd.PrintErrf("Warning: %s\n", problem)
+	}
+	return nil
+}
 
-		if err := docker.BuildImage(cfg, projectPath, releaseTag, releaseContext); err != nil {
+func release(cmd *cobra.Command, cfg config.Config) error {
+	err := runWithHooks(cfg, releaseTag, config.HookPreBuild, config.HookPostBuild, func() error {
+		if err := docker.BuildImage(cfg, projectPath, releaseTag, releaseContext, docker.BuildOptions{NoCache: releaseNoCache, Pull: releasePull}); err != nil {
 			return fmt.Errorf("build failed: %w", err)
 		}
-		if err := docker.PushImage(cfg, projectPath, releaseTag); err != nil {
-			return fmt.Errorf("push failed: %w", err)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if cfg.Scan.Database != "" {
+		if err := scanImages(cmd, cfg, releaseTag); err != nil {
+			return fmt.Errorf("scan failed: %w", err)
 		}
-		if err := docker.Deploy(cfg, releaseTag); err != nil {
-			return fmt.Errorf("deploy failed: %w", err)
+	}
+
+	return withLock(cfg, releaseTag, func() error {
+		if cfg.Build.Mode != config.BuildModeRemote {
+			err := runWithHooks(cfg, releaseTag, config.HookPrePush, "", func() error {
+				if err := docker.PushImage(cfg, projectPath, releaseTag); err != nil {
+					return fmt.Errorf("push failed: %w", err)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
 		}
 
-		return nil
-	},
+		return runWithHooks(cfg, releaseTag, config.HookPreDeploy, config.HookPostDeploy, func() error {
+			if err := docker.Deploy(cfg, projectPath, releaseTag); err != nil {
+				return fmt.Errorf("deploy failed: %w", err)
+			}
+			return nil
+		})
+	})
 }
 
 func init() {
-	releaseCmd.Flags().StringVar(&releaseTag, "tag", "", "image tag suffix (default: <yyyymmdd-hhmm>-<shortsha>)")
+	releaseCmd.Flags().StringVar(&releaseTag, "tag", "", "image tag suffix (default: from tag_strategy)")
 	releaseCmd.Flags().StringVar(&releaseContext, "context", ".", "build context path")
+	releaseCmd.Flags().BoolVar(&releaseNoCache, "no-cache", false, "do not use cache when building images")
+	releaseCmd.Flags().BoolVar(&releasePull, "pull", false, "always pull newer versions of base images")
+	releaseCmd.Flags().StringSliceVar(&releaseOnly, "only", nil, "limit the release to these images or containers")
+	releaseCmd.Flags().BoolVar(&releaseDirty, "dirty", false, "refuse to release uncommitted or unpushed changes, whatever dirty_policy says")
 	rootCmd.AddCommand(releaseCmd)
 }
@@ -9,6 +9,7 @@ import (
 var (
 	projectPath string
 	configPath  string
+	environment string
 )
 
 var rootCmd = &cobra.Command{
@@ -24,8 +25,21 @@ func Execute() error {
 func init() {
 	rootCmd.PersistentFlags().StringVar(&projectPath, "project", ".", "path to project directory")
 	rootCmd.PersistentFlags().StringVar(&configPath, "config", "airo.yaml", "config file path, relative to --project")
+	rootCmd.PersistentFlags().StringVar(&environment, "env", "", "environment from the environments section to target")
 }
 
 func loadConfig() (config.Config, error) {
-	return config.Load(projectPath, configPath)
+	cfg, err := config.Load(projectPath, configPath)
+	if err != nil {
+		return config.Config{}, err
+	}
+	return config.ForEnvironment(cfg, environment)
+}
+
+func loadSelectedConfig(names []string) (config.Config, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return config.Config{}, err
+	}
+	return config.Select(cfg, names)
 }
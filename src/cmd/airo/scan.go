@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"bypirob/airo/src/internal/config"
+	"bypirob/airo/src/internal/docker"
+	"bypirob/airo/src/internal/sbom"
+)
+
+var scanCmd = &cobra.Command{
+	Use:   "scan <tag> [image|container...]",
+	Short: "Check image SBOMs against the offline vulnerability database",
+	Args:  cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadSelectedConfig(args[1:])
+		if err != nil {
+			return err
+		}
+		if cfg.Scan.Database == "" {
+			return fmt.Errorf("scan.database is required for scan")
+		}
+		return scanImages(cmd, cfg, args[0])
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(scanCmd)
+}
+
+func scanImages(cmd *cobra.Command, cfg config.Config, tag string) error {
+	vulns, err := sbom.LoadDatabase(resolveProjectPath(cfg.Scan.Database))
+	if err != nil {
+		return err
+	}
+	docs, err := docker.LoadSBOMs(cfg, projectPath, tag)
+	if err != nil {
+		return err
+	}
+
+	report := sbom.Scan(docs, vulns, cfg.Scan.FailOn)
+	for _, image := range report.Empty {
+		cmd.PrintErrf("Warning: no apk or dpkg packages found in %s, it cannot be scanned\n", image)
+	}
+	if len(report.Findings) == 0 && len(report.Empty) == 0 {
+		cmd.Println("No known vulnerabilities found")
+	} else {
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "IMAGE\tPACKAGE\tVERSION\tID\tSEVERITY\tFIXED")
+		for _, finding := range report.Findings {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", finding.Image, finding.Package, finding.InstalledVersion,
+				finding.ID, finding.Severity, orDash(finding.Fixed))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Deploy.Type == "ssh" {
+		if err := docker.SaveScanReport(cfg, tag, report); err != nil {
+			cmd.PrintErrln(err)
+		}
+	}
+	if !report.Blocked {
+		return nil
+	}
+	threshold := sbom.SeverityRank(cfg.Scan.FailOn)
+	for _, finding := range report.Findings {
+		if sbom.SeverityRank(finding.Severity) >= threshold {
+			return fmt.Errorf("found vulnerabilities at or above %s severity", cfg.Scan.FailOn)
+		}
+	}
+	return fmt.Errorf("%d images have no packages to scan; set scan.fail_on to none to release them anyway", len(report.Empty))
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+
+	"bypirob/airo/src/internal/docker"
+)
+
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Manage encrypted secrets",
+}
+
+var secretsEditCmd = &cobra.Command{
+	Use:   "edit <file>",
+	Short: "Decrypt a secret file in $EDITOR and encrypt it again",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		return docker.EditSecret(cfg, resolveProjectPath(args[0]))
+	},
+}
+
+var secretsKeygenCmd = &cobra.Command{
+	Use:   "keygen",
+	Short: "Generate an age identity for secrets",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+
+		recipient, err := docker.GenerateSecretKey(cfg)
+		if err != nil {
+			return err
+		}
+
+		cmd.Printf("Created %s\n", cfg.Secrets.Identity)
+		cmd.Printf("Public key: %s\n", recipient)
+		cmd.Println("Add it to secrets.recipients so others can encrypt for you.")
+		return nil
+	},
+}
+
+func init() {
+	secretsCmd.AddCommand(secretsEditCmd, secretsKeygenCmd)
+	rootCmd.AddCommand(secretsCmd)
+}
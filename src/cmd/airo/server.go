@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"bypirob/airo/src/internal/docker"
+)
+
+var (
+	serverSetupSSHUser    string
+	serverSetupDeployUser string
+	serverSetupPublicKey  string
+)
+
+var serverCmd = &cobra.Command{
+	Use:   "server",
+	Short: "Manage the deploy server",
+}
+
+var serverSetupCmd = &cobra.Command{
+	Use:   "setup",
+	Short: "Install Docker and prepare the server for deploys",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		if cfg.Deploy.Type != "ssh" {
+			return fmt.Errorf("deploy.type must be ssh for server setup")
+		}
+
+		opts := docker.SetupOptions{
+			DeployUser:    serverSetupDeployUser,
+			PublicKeyPath: serverSetupPublicKey,
+		}
+		if opts.DeployUser == "" {
+			opts.DeployUser = cfg.Deploy.SSH.User
+		}
+		if opts.PublicKeyPath == "" && cfg.Deploy.SSH.IdentityFile != "" {
+			opts.PublicKeyPath = docker.DefaultPublicKey(cfg)
+			if opts.PublicKeyPath == "" {
+				cmd.PrintErrf("Warning: %s.pub not found, skipping key authorization; pass --public-key to authorize a key\n", cfg.Deploy.SSH.IdentityFile)
+			}
+		}
+		if serverSetupSSHUser != "" {
+			cfg.Deploy.SSH.User = serverSetupSSHUser
+		}
+
+		steps, err := docker.SetupServer(cfg, opts)
+		changed := 0
+		for _, step := range steps {
+			status := "ok"
+			if step.Changed {
+				status = "changed"
+				changed++
+			}
+			cmd.Printf("%-8s %s\n", status, step.Message)
+		}
+		if err != nil {
+			return err
+		}
+
+		cmd.Printf("%d changed, %d unchanged\n", changed, len(steps)-changed)
+		return nil
+	},
+}
+
+func init() {
+	serverSetupCmd.Flags().StringVar(&serverSetupSSHUser, "ssh-user", "", "user to connect as for setup (default: deploy.ssh.user)")
+	serverSetupCmd.Flags().StringVar(&serverSetupDeployUser, "deploy-user", "", "user to create for deploys (default: deploy.ssh.user)")
+	serverSetupCmd.Flags().StringVar(&serverSetupPublicKey, "public-key", "", "public key to authorize for the deploy user (default: deploy.ssh.identity_file + .pub)")
+	serverCmd.AddCommand(serverSetupCmd)
+	rootCmd.AddCommand(serverCmd)
+}
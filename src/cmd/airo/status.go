@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 
@@ -26,10 +27,37 @@ var statusCmd = &cobra.Command{
 		}
 
 		cmd.Println(status)
-		return nil
+
+		details, err := docker.ContainerDetails(cfg)
+		if err != nil {
+			return err
+		}
+
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "CONTAINER\tSTATUS\tIMAGE\tREVISION\tCREATED\tBUILDER")
+		for _, info := range details {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", info.Name, info.Status, orDash(info.Image),
+				orDash(shortRevision(info.Labels[docker.LabelRevision])),
+				orDash(info.Labels[docker.LabelCreated]), orDash(info.Labels[docker.LabelBuilder]))
+		}
+		return w.Flush()
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(statusCmd)
 }
+
+func shortRevision(revision string) string {
+	if len(revision) > 12 {
+		return revision[:12]
+	}
+	return revision
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -1,12 +1,19 @@
 package main
 
 import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
 	"github.com/spf13/cobra"
 
 	"bypirob/airo/src/internal/docker"
 )
 
-var tagsRemote bool
+var (
+	tagsRemote bool
+	tagsHost   bool
+)
 
 var tagsCmd = &cobra.Command{
 	Use:   "tags",
@@ -16,21 +23,53 @@ var tagsCmd = &cobra.Command{
 		if err != nil {
 			return err
 		}
+		if tagsRemote && tagsHost {
+			return fmt.Errorf("--remote and --host cannot be used together")
+		}
 
-		tags, err := docker.Tags(cfg, tagsRemote)
+		source := docker.TagSourceLocal
+		switch {
+		case tagsRemote:
+			source = docker.TagSourceRegistry
+		case tagsHost:
+			if cfg.Deploy.Type != "ssh" {
+				return fmt.Errorf("deploy.type must be ssh for --host")
+			}
+			source = docker.TagSourceHost
+		}
+
+		images, err := docker.Tags(cfg, source)
 		if err != nil {
 			return err
 		}
 
-		for _, tag := range tags {
-			cmd.Println(tag)
+		deployed := map[string][]string{}
+		if cfg.Deploy.Type == "ssh" {
+			deployed, err = docker.DeployedTags(cfg)
+			if err != nil {
+				cmd.PrintErrf("Could not read deployed tags: %v\n", err)
+			}
 		}
 
-		return nil
+		w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TAG\tCREATED\tSIZE\tDEPLOYED")
+		for _, image := range images {
+			created := "-"
+			if !image.Created.IsZero() {
+				created = image.Created.Local().Format("2006-01-02 15:04")
+			}
+			size := image.Size
+			if size == "" {
+				size = "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", image.Ref(), created, size, strings.Join(deployed[image.Ref()], ","))
+		}
+		return w.Flush()
 	},
 }
 
 func init() {
 	tagsCmd.Flags().BoolVar(&tagsRemote, "remote", false, "list tags from the registry")
+	tagsCmd.Flags().BoolVar(&tagsHost, "host", false, "list images on the deploy server")
 	rootCmd.AddCommand(tagsCmd)
 }
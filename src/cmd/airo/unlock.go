@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"bypirob/airo/src/internal/config"
+	"bypirob/airo/src/internal/docker"
+)
+
+var unlockForce bool
+
+var unlockCmd = &cobra.Command{
+	Use:   "unlock",
+	Short: "Release the deploy lock on the server",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			return err
+		}
+		if cfg.Deploy.Type != "ssh" {
+			return fmt.Errorf("deploy.type must be ssh for unlock")
+		}
+
+		info, err := docker.Unlock(cfg, unlockForce)
+		if err != nil {
+			return err
+		}
+
+		cmd.Printf("Released deploy lock held by %s\n", info)
+		return nil
+	},
+}
+
+func init() {
+	unlockCmd.Flags().BoolVar(&unlockForce, "force", false, "release the lock even if another user holds it")
+	rootCmd.AddCommand(unlockCmd)
+}
+
+func withLock(cfg config.Config, tag string, run func() error) error {
+	release, err := docker.AcquireLock(cfg, tag)
+	if err != nil {
+		return err
+	}
+
+	err = run()
+	if unlockErr := release(); unlockErr != nil && err == nil {
+		err = unlockErr
+	}
+	return err
+}
@@ -2,41 +2,268 @@ package config
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/goccy/go-yaml"
 )
 
 const (
-	DefaultBaseImage  = "node:24-alpine"
-	DefaultTargetArch = "linux/amd64"
+	DefaultBaseImage   = "node:24-alpine"
+	DefaultTargetArch  = "linux/amd64"
+	DefaultLockTimeout = 30 * time.Minute
+	DefaultProxyImage  = "caddy:2-alpine"
+	DefaultProxyName   = "airo-proxy"
+	DefaultProxyNet    = "airo-proxy"
+	DefaultAgeIdentity = "~/.config/airo/age.key"
+	DefaultKeepLast    = 5
+	DefaultScanFailOn  = "high"
+)
+
+const (
+	TagStrategyTimestampSHA = "timestamp-sha"
+	TagStrategyGitDescribe  = "git-describe"
+	TagStrategyGitTag       = "git-tag"
+	TagStrategySemver       = "semver"
+	TagStrategyTemplate     = "template"
+)
+
+const (
+	BuildModeLocal  = "local"
+	BuildModeRemote = "remote"
+)
+
+const (
+	CacheLocal    = "local"
+	CacheInline   = "inline"
+	CacheRegistry = "registry"
+)
+
+const (
+	DirtyRefuse = "refuse"
+	DirtyWarn   = "warn"
+	DirtySuffix = "suffix"
+)
+
+const (
+	ConditionStarted   = "started"
+	ConditionHealthy   = "healthy"
+	ConditionCompleted = "completed"
+)
+
+const (
+	PhaseBeforeDeploy = "before_deploy"
+	PhaseAfterDeploy  = "after_deploy"
+)
+
+const (
+	HookPreBuild   = "pre_build"
+	HookPostBuild  = "post_build"
+	HookPrePush    = "pre_push"
+	HookPreDeploy  = "pre_deploy"
+	HookPostDeploy = "post_deploy"
+	HookOnFailure  = "on_failure"
 )
 
 type Config struct {
-	Images map[string]ImageConfig `yaml:"images"`
-	Deploy DeployConfig           `yaml:"deploy"`
+	Images       map[string]ImageConfig       `yaml:"images"`
+	Deploy       DeployConfig                 `yaml:"deploy"`
+	Jobs         []JobConfig                  `yaml:"jobs"`
+	Hooks        HooksConfig                  `yaml:"hooks"`
+	Networks     map[string]ResourceConfig    `yaml:"networks"`
+	Volumes      map[string]ResourceConfig    `yaml:"volumes"`
+	Secrets      SecretsConfig                `yaml:"secrets"`
+	Retention    RetentionConfig              `yaml:"retention"`
+	Environments map[string]EnvironmentConfig `yaml:"environments"`
+	TagStrategy  string                       `yaml:"tag_strategy"`
+	TagTemplate  string                       `yaml:"tag_template"`
+	DirtyPolicy  string                       `yaml:"dirty_policy"`
+	Scan         ScanConfig                   `yaml:"scan"`
+	Build        BuildConfig                  `yaml:"build"`
+	Environment  string                       `yaml:"-"`
+}
+
+type EnvironmentConfig struct {
+	Type     string         `yaml:"type"`
+	SSH      SSHConfig      `yaml:"ssh"`
+	Registry RegistryConfig `yaml:"registry"`
+}
+
+type RetentionConfig struct {
+	KeepLast      *int   `yaml:"keep_last"`
+	KeepNewerThan string `yaml:"keep_newer_than"`
 }
 
 type ImageConfig struct {
-	BaseImage  string `yaml:"base_image"`
-	TargetArch string `yaml:"target_arch"`
+	BaseImage    string            `yaml:"base_image"`
+	RuntimeImage string            `yaml:"runtime_image"`
+	TargetArch   Platforms         `yaml:"target_arch"`
+	Labels       map[string]string `yaml:"labels"`
+	SBOM         bool              `yaml:"sbom"`
+	CacheFrom    []CacheConfig     `yaml:"cache_from"`
+	CacheTo      []CacheConfig     `yaml:"cache_to"`
+	Secrets      []BuildSecret     `yaml:"secrets"`
+	SSH          []string          `yaml:"ssh"`
+}
+
+type Platforms []string
+
+func (p *Platforms) UnmarshalYAML(unmarshal func(any) error) error {
+	var single string
+	if err := unmarshal(&single); err == nil {
+		*p = Platforms{}
+		if single != "" {
+			*p = Platforms{single}
+		}
+		return nil
+	}
+
+	var list []string
+	if err := unmarshal(&list); err != nil {
+		return fmt.Errorf("target_arch must be a platform or a list of platforms")
+	}
+	*p = list
+	return nil
+}
+
+func (p Platforms) String() string {
+	return strings.Join(p, ",")
+}
+
+type BuildSecret struct {
+	ID   string `yaml:"id"`
+	File string `yaml:"file"`
+	Env  string `yaml:"env"`
+}
+
+func (image ImageConfig) ExportsCache() bool {
+	for _, cache := range image.CacheTo {
+		if cache.Type != CacheInline {
+			return true
+		}
+	}
+	return false
+}
+
+type CacheConfig struct {
+	Type string `yaml:"type"`
+	Ref  string `yaml:"ref"`
+	Path string `yaml:"path"`
+	Mode string `yaml:"mode"`
+}
+
+type BuildConfig struct {
+	Mode string `yaml:"mode"`
+}
+
+type ScanConfig struct {
+	Database string `yaml:"database"`
+	FailOn   string `yaml:"fail_on"`
 }
 
 type DeployConfig struct {
-	Type       string            `yaml:"type"`
-	Containers []ContainerConfig `yaml:"containers"`
-	SSH        SSHConfig         `yaml:"ssh"`
-	Registry   RegistryConfig    `yaml:"registry"`
+	Type        string            `yaml:"type"`
+	Containers  []ContainerConfig `yaml:"containers"`
+	SSH         SSHConfig         `yaml:"ssh"`
+	Registry    RegistryConfig    `yaml:"registry"`
+	LockTimeout string            `yaml:"lock_timeout"`
+	Proxy       ProxyConfig       `yaml:"proxy"`
+}
+
+type ProxyConfig struct {
+	Name    string `yaml:"name"`
+	Image   string `yaml:"image"`
+	Network string `yaml:"network"`
+	Email   string `yaml:"email"`
 }
 
 type ContainerConfig struct {
+	Name      string             `yaml:"name"`
+	Image     string             `yaml:"image"`
+	Port      int                `yaml:"port"`
+	AppPort   int                `yaml:"app_port"`
+	Networks  []string           `yaml:"networks"`
+	EnvFile   string             `yaml:"env_file"`
+	DependsOn []DependencyConfig `yaml:"depends_on"`
+	Domains   []string           `yaml:"domains"`
+	Volumes   []string           `yaml:"volumes"`
+	Secrets   ContainerSecrets   `yaml:"secrets"`
+}
+
+type ContainerSecrets struct {
+	EnvFile string         `yaml:"env_file"`
+	Files   []SecretConfig `yaml:"files"`
+}
+
+type SecretConfig struct {
+	Source string `yaml:"source"`
+	Target string `yaml:"target"`
+	Mode   string `yaml:"mode"`
+	Owner  string `yaml:"owner"`
+}
+
+type SecretsConfig struct {
+	Identity   string   `yaml:"identity"`
+	Recipients []string `yaml:"recipients"`
+}
+
+type DependencyConfig struct {
+	Name      string `yaml:"name"`
+	Condition string `yaml:"condition"`
+}
+
+type JobConfig struct {
 	Name     string   `yaml:"name"`
 	Image    string   `yaml:"image"`
-	Port     int      `yaml:"port"`
-	AppPort  int      `yaml:"app_port"`
-	Networks []string `yaml:"networks"`
+	Command  []string `yaml:"command"`
 	EnvFile  string   `yaml:"env_file"`
+	Networks []string `yaml:"networks"`
+	Volumes  []string `yaml:"volumes"`
+	Phase    string   `yaml:"phase"`
+}
+
+type ResourceConfig struct {
+	Driver  string            `yaml:"driver"`
+	Options map[string]string `yaml:"options"`
+	Labels  map[string]string `yaml:"labels"`
+}
+
+type HooksConfig struct {
+	PreBuild   []HookConfig `yaml:"pre_build"`
+	PostBuild  []HookConfig `yaml:"post_build"`
+	PrePush    []HookConfig `yaml:"pre_push"`
+	PreDeploy  []HookConfig `yaml:"pre_deploy"`
+	PostDeploy []HookConfig `yaml:"post_deploy"`
+	OnFailure  []HookConfig `yaml:"on_failure"`
+}
+
+type HookConfig struct {
+	Run    string `yaml:"run"`
+	Remote bool   `yaml:"remote"`
+}
+
+func (h HooksConfig) Get(name string) []HookConfig {
+	switch name {
+	case HookPreBuild:
+		return h.PreBuild
+	case HookPostBuild:
+		return h.PostBuild
+	case HookPrePush:
+		return h.PrePush
+	case HookPreDeploy:
+		return h.PreDeploy
+	case HookPostDeploy:
+		return h.PostDeploy
+	case HookOnFailure:
+		return h.OnFailure
+	default:
+		return nil
+	}
 }
 
 type SSHConfig struct {
@@ -65,12 +292,22 @@ func Load(projectPath, configPath string) (Config, error) {
 		return Config{}, fmt.Errorf("read config %s: %w", fullPath, err)
 	}
 
-	var cfg Config
-	if err := yaml.Unmarshal(data, &cfg); err != nil {
+	cfg, err := decode(data)
+	if err != nil {
 		return Config{}, fmt.Errorf("parse config %s: %w", fullPath, err)
 	}
+	if err := validate(cfg); err != nil {
+		return Config{}, err
+	}
 
-	applyDefaults(&cfg)
+	return cfg, nil
+}
+
+func Parse(data []byte) (Config, error) {
+	cfg, err := decode(data)
+	if err != nil {
+		return Config{}, fmt.Errorf("parse config: %w", err)
+	}
 	if err := validate(cfg); err != nil {
 		return Config{}, err
 	}
@@ -78,44 +315,307 @@ func Load(projectPath, configPath string) (Config, error) {
 	return cfg, nil
 }
 
+func decode(data []byte) (Config, error) {
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return Config{}, err
+	}
+	applyDefaults(&cfg)
+	return cfg, nil
+}
+
+func (c Config) ImageNames() []string {
+	return slices.Sorted(maps.Keys(c.Images))
+}
+
+func (c Config) Container(name string) (ContainerConfig, bool) {
+	for _, container := range c.Deploy.Containers {
+		if container.Name == name {
+			return container, true
+		}
+	}
+	return ContainerConfig{}, false
+}
+
+func ForEnvironment(cfg Config, name string) (Config, error) {
+	if name == "" {
+		return cfg, nil
+	}
+	env, ok := cfg.Environments[name]
+	if !ok {
+		return Config{}, fmt.Errorf("environment %q is not defined in environments", name)
+	}
+
+	selected := cfg
+	selected.Environment = name
+	if env.Type != "" {
+		selected.Deploy.Type = env.Type
+	}
+	if env.SSH.Host != "" {
+		selected.Deploy.SSH = env.SSH
+	}
+	if env.Registry.Repository != "" || env.Registry.RegistryURL != "" {
+		selected.Deploy.Registry = env.Registry
+	}
+	return selected, nil
+}
+
+func Select(cfg Config, names []string) (Config, error) {
+	if len(names) == 0 {
+		return cfg, nil
+	}
+
+	images := make(map[string]struct{}, len(names))
+	containers := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		matched := false
+		if _, ok := cfg.Images[name]; ok {
+			images[name] = struct{}{}
+			for _, container := range cfg.Deploy.Containers {
+				if container.Image == name {
+					containers[container.Name] = struct{}{}
+				}
+			}
+			matched = true
+		}
+		for _, container := range cfg.Deploy.Containers {
+			if container.Name == name {
+				containers[container.Name] = struct{}{}
+				images[container.Image] = struct{}{}
+				matched = true
+			}
+		}
+		if !matched {
+			return Config{}, fmt.Errorf("%q is not defined in images or deploy.containers", name)
+		}
+	}
+
+	selected := cfg
+	selected.Images = make(map[string]ImageConfig, len(images))
+	for name := range images {
+		selected.Images[name] = cfg.Images[name]
+	}
+	selected.Deploy.Containers = make([]ContainerConfig, 0, len(containers))
+	for _, container := range cfg.Deploy.Containers {
+		if _, ok := containers[container.Name]; ok {
+			selected.Deploy.Containers = append(selected.Deploy.Containers, container)
+		}
+	}
+	selected.Jobs = make([]JobConfig, 0, len(cfg.Jobs))
+	for _, job := range cfg.Jobs {
+		if _, ok := images[job.Image]; ok {
+			selected.Jobs = append(selected.Jobs, job)
+		}
+	}
+
+	return selected, nil
+}
+
 func applyDefaults(cfg *Config) {
 	for name, image := range cfg.Images {
 		if image.BaseImage == "" {
 			image.BaseImage = DefaultBaseImage
 		}
-		if image.TargetArch == "" {
-			image.TargetArch = DefaultTargetArch
-		}
 		cfg.Images[name] = image
 	}
+	if cfg.Deploy.Proxy.Name == "" {
+		cfg.Deploy.Proxy.Name = DefaultProxyName
+	}
+	if cfg.Deploy.Proxy.Image == "" {
+		cfg.Deploy.Proxy.Image = DefaultProxyImage
+	}
+	if cfg.Deploy.Proxy.Network == "" {
+		cfg.Deploy.Proxy.Network = DefaultProxyNet
+	}
+	if cfg.Build.Mode == "" {
+		cfg.Build.Mode = BuildModeLocal
+	}
+	if cfg.Scan.FailOn == "" {
+		cfg.Scan.FailOn = DefaultScanFailOn
+	}
+	if cfg.DirtyPolicy == "" {
+		cfg.DirtyPolicy = DirtyWarn
+	}
+	if cfg.TagStrategy == "" {
+		cfg.TagStrategy = TagStrategyTimestampSHA
+	}
+	if cfg.Retention.KeepLast == nil {
+		keepLast := DefaultKeepLast
+		cfg.Retention.KeepLast = &keepLast
+	}
+	if cfg.Secrets.Identity == "" {
+		cfg.Secrets.Identity = DefaultAgeIdentity
+	}
+	if cfg.Deploy.LockTimeout == "" {
+		cfg.Deploy.LockTimeout = DefaultLockTimeout.String()
+	}
+	for i, job := range cfg.Jobs {
+		if job.Phase == "" {
+			cfg.Jobs[i].Phase = PhaseBeforeDeploy
+		}
+	}
+	for i, container := range cfg.Deploy.Containers {
+		for j, dependency := range container.DependsOn {
+			if dependency.Condition == "" {
+				cfg.Deploy.Containers[i].DependsOn[j].Condition = ConditionStarted
+			}
+		}
+	}
 }
 
-func validate(cfg Config) error {
-	if cfg.Deploy.Type == "" {
-		return fmt.Errorf("deploy.type is required")
+func DeployOrder(containers []ContainerConfig) ([]ContainerConfig, error) {
+	indexes := make(map[string]int, len(containers))
+	for i, container := range containers {
+		indexes[container.Name] = i
 	}
-	switch cfg.Deploy.Type {
-	case "ssh", "registry":
-	default:
-		return fmt.Errorf("deploy.type must be ssh or registry")
+
+	ordered := make([]ContainerConfig, 0, len(containers))
+	placed := make([]bool, len(containers))
+	for len(ordered) < len(containers) {
+		progressed := false
+		for i, container := range containers {
+			if placed[i] {
+				continue
+			}
+			ready := true
+			for _, dependency := range container.DependsOn {
+				if idx, ok := indexes[dependency.Name]; ok && !placed[idx] {
+					ready = false
+					break
+				}
+			}
+			if !ready {
+				continue
+			}
+			placed[i] = true
+			ordered = append(ordered, container)
+			progressed = true
+		}
+		if !progressed {
+			pending := make([]string, 0)
+			for i, container := range containers {
+				if !placed[i] {
+					pending = append(pending, container.Name)
+				}
+			}
+			return nil, fmt.Errorf("deploy.containers.depends_on has a cycle between %s", strings.Join(pending, ", "))
+		}
 	}
 
-	if cfg.Deploy.Type == "ssh" && cfg.Deploy.SSH.Host == "" {
-		return fmt.Errorf("deploy.ssh.host is required for ssh deploys")
+	return ordered, nil
+}
+
+func validate(cfg Config) error {
+	if err := validateTarget(cfg.Deploy, "deploy"); err != nil {
+		return err
 	}
-	if cfg.Deploy.Type == "registry" && cfg.Deploy.Registry.Repository == "" {
-		return fmt.Errorf("deploy.registry.repository is required for registry deploys")
+	for _, name := range slices.Sorted(maps.Keys(cfg.Environments)) {
+		env, err := ForEnvironment(cfg, name)
+		if err != nil {
+			return err
+		}
+		if err := validateTarget(env.Deploy, "environments."+name); err != nil {
+			return err
+		}
 	}
 
 	if len(cfg.Images) == 0 {
 		return fmt.Errorf("images is required")
 	}
+	for _, name := range cfg.ImageNames() {
+		image := cfg.Images[name]
+		for _, platform := range image.TargetArch {
+			if !strings.HasPrefix(platform, "linux/") {
+				return fmt.Errorf("images.%s.target_arch %q must be a linux platform such as linux/amd64", name, platform)
+			}
+		}
+		for _, cache := range image.CacheFrom {
+			if err := validateCache(cache, "images."+name+".cache_from"); err != nil {
+				return err
+			}
+			if cache.Type == CacheInline {
+				return fmt.Errorf("images.%s.cache_from cannot use type inline; use type registry with the image ref", name)
+			}
+		}
+		for _, cache := range image.CacheTo {
+			if err := validateCache(cache, "images."+name+".cache_to"); err != nil {
+				return err
+			}
+		}
+		secretIDs := map[string]bool{}
+		for _, secret := range image.Secrets {
+			if secret.ID == "" {
+				return fmt.Errorf("images.%s.secrets.id is required", name)
+			}
+			if secretIDs[secret.ID] {
+				return fmt.Errorf("images.%s.secrets has duplicate id %q", name, secret.ID)
+			}
+			secretIDs[secret.ID] = true
+			if (secret.File == "") == (secret.Env == "") {
+				return fmt.Errorf("images.%s.secrets.%s must set exactly one of file or env", name, secret.ID)
+			}
+		}
+		for _, ssh := range image.SSH {
+			if id, _, _ := strings.Cut(ssh, "="); id == "" {
+				return fmt.Errorf("images.%s.ssh %q must be default or id=path", name, ssh)
+			}
+		}
+	}
+
+	if timeout, err := time.ParseDuration(cfg.Deploy.LockTimeout); err != nil || timeout <= 0 {
+		return fmt.Errorf("deploy.lock_timeout must be a positive duration such as 30m")
+	}
+	switch cfg.Build.Mode {
+	case BuildModeLocal:
+	case BuildModeRemote:
+		if cfg.Deploy.Type != "ssh" {
+			return fmt.Errorf("build.mode remote requires deploy.type ssh")
+		}
+		if cfg.Scan.Database != "" {
+			return fmt.Errorf("scan.database is not supported with build.mode remote")
+		}
+		for _, name := range cfg.ImageNames() {
+			if cfg.Images[name].ExportsCache() {
+				return fmt.Errorf("images.%s.cache_to supports only type inline with build.mode remote, the server's docker driver cannot export other caches", name)
+			}
+		}
+	default:
+		return fmt.Errorf("build.mode must be local or remote")
+	}
+	switch cfg.Scan.FailOn {
+	case "low", "medium", "high", "critical", "none":
+	default:
+		return fmt.Errorf("scan.fail_on must be low, medium, high, critical or none")
+	}
+	switch cfg.DirtyPolicy {
+	case DirtyRefuse, DirtyWarn, DirtySuffix:
+	default:
+		return fmt.Errorf("dirty_policy must be refuse, warn or suffix")
+	}
+	switch cfg.TagStrategy {
+	case TagStrategyTimestampSHA, TagStrategyGitDescribe, TagStrategyGitTag, TagStrategySemver:
+	case TagStrategyTemplate:
+		if cfg.TagTemplate == "" {
+			return fmt.Errorf("tag_template is required when tag_strategy is template")
+		}
+	default:
+		return fmt.Errorf("tag_strategy must be timestamp-sha, git-describe, git-tag, semver or template")
+	}
+	if cfg.Retention.KeepLast != nil && *cfg.Retention.KeepLast < 0 {
+		return fmt.Errorf("retention.keep_last must not be negative")
+	}
+	if cfg.Retention.KeepNewerThan != "" {
+		if _, err := time.ParseDuration(cfg.Retention.KeepNewerThan); err != nil {
+			return fmt.Errorf("retention.keep_newer_than must be a duration such as 168h")
+		}
+	}
 
 	if len(cfg.Deploy.Containers) == 0 {
 		return fmt.Errorf("deploy.containers is required")
 	}
 
 	seenNames := make(map[string]struct{}, len(cfg.Deploy.Containers))
+	seenDomains := make(map[string]struct{})
 	for _, container := range cfg.Deploy.Containers {
 		if container.Name == "" {
 			return fmt.Errorf("deploy.containers.name is required")
@@ -133,7 +633,167 @@ func validate(cfg Config) error {
 		if container.Port != 0 && container.AppPort == 0 {
 			return fmt.Errorf("deploy.containers.app_port is required when deploy.containers.port is set")
 		}
+		if len(container.Domains) > 0 && container.AppPort == 0 {
+			return fmt.Errorf("deploy.containers.app_port is required when deploy.containers.domains is set")
+		}
+		for _, domain := range container.Domains {
+			if domain == "" || strings.ContainsAny(domain, " \t{}") {
+				return fmt.Errorf("deploy.containers.domains %q is not a valid domain", domain)
+			}
+			if _, exists := seenDomains[domain]; exists {
+				return fmt.Errorf("deploy.containers.domains %q must be unique", domain)
+			}
+			seenDomains[domain] = struct{}{}
+		}
+	}
+
+	for _, container := range cfg.Deploy.Containers {
+		for _, dependency := range container.DependsOn {
+			if _, ok := seenNames[dependency.Name]; !ok {
+				return fmt.Errorf("deploy.containers.depends_on %q of %q is not defined in deploy.containers", dependency.Name, container.Name)
+			}
+			if dependency.Name == container.Name {
+				return fmt.Errorf("deploy.containers.depends_on of %q cannot reference itself", container.Name)
+			}
+			switch dependency.Condition {
+			case ConditionStarted, ConditionHealthy, ConditionCompleted:
+			default:
+				return fmt.Errorf("deploy.containers.depends_on.condition must be started, healthy or completed")
+			}
+		}
+	}
+	if _, err := DeployOrder(cfg.Deploy.Containers); err != nil {
+		return err
+	}
+
+	for _, container := range cfg.Deploy.Containers {
+		seenTargets := make(map[string]struct{}, len(container.Secrets.Files))
+		for _, secret := range container.Secrets.Files {
+			if secret.Source == "" || secret.Target == "" {
+				return fmt.Errorf("deploy.containers.secrets.files requires source and target")
+			}
+			if !strings.HasPrefix(secret.Target, "/") {
+				return fmt.Errorf("deploy.containers.secrets.files.target %q must be an absolute path", secret.Target)
+			}
+			if _, exists := seenTargets[secret.Target]; exists {
+				return fmt.Errorf("deploy.containers.secrets.files.target %q must be unique", secret.Target)
+			}
+			seenTargets[secret.Target] = struct{}{}
+			if secret.Mode != "" {
+				if mode, err := strconv.ParseUint(secret.Mode, 8, 32); err != nil || mode > 0o777 {
+					return fmt.Errorf("deploy.containers.secrets.files.mode %q must be an octal mode such as 0440", secret.Mode)
+				}
+			}
+			if secret.Owner != "" && !validOwner(secret.Owner) {
+				return fmt.Errorf("deploy.containers.secrets.files.owner %q must be a numeric uid or uid:gid", secret.Owner)
+			}
+		}
+		if err := validateVolumes("deploy.containers.volumes", container.Volumes); err != nil {
+			return err
+		}
+	}
+	for _, job := range cfg.Jobs {
+		if err := validateVolumes("jobs.volumes", job.Volumes); err != nil {
+			return err
+		}
+	}
+
+	seenJobs := make(map[string]struct{}, len(cfg.Jobs))
+	for _, job := range cfg.Jobs {
+		if job.Name == "" {
+			return fmt.Errorf("jobs.name is required")
+		}
+		if _, exists := seenJobs[job.Name]; exists {
+			return fmt.Errorf("jobs.name %q must be unique", job.Name)
+		}
+		seenJobs[job.Name] = struct{}{}
+		if job.Image == "" {
+			return fmt.Errorf("jobs.image is required")
+		}
+		if _, ok := cfg.Images[job.Image]; !ok {
+			return fmt.Errorf("jobs.image %q is not defined in images", job.Image)
+		}
+		switch job.Phase {
+		case PhaseBeforeDeploy, PhaseAfterDeploy:
+		default:
+			return fmt.Errorf("jobs.phase must be before_deploy or after_deploy")
+		}
+	}
+
+	for _, name := range []string{HookPreBuild, HookPostBuild, HookPrePush, HookPreDeploy, HookPostDeploy, HookOnFailure} {
+		for _, hook := range cfg.Hooks.Get(name) {
+			if hook.Run == "" {
+				return fmt.Errorf("hooks.%s.run is required", name)
+			}
+			if hook.Remote && cfg.Deploy.SSH.Host == "" {
+				return fmt.Errorf("deploy.ssh.host is required for remote hooks.%s", name)
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateTarget(deploy DeployConfig, field string) error {
+	if deploy.Type == "" {
+		return fmt.Errorf("%s.type is required", field)
+	}
+	switch deploy.Type {
+	case "ssh", "registry":
+	default:
+		return fmt.Errorf("%s.type must be ssh or registry", field)
+	}
+
+	if deploy.Type == "ssh" && deploy.SSH.Host == "" {
+		return fmt.Errorf("%s.ssh.host is required for ssh deploys", field)
+	}
+	if deploy.Type == "registry" && deploy.Registry.Repository == "" {
+		return fmt.Errorf("%s.registry.repository is required for registry deploys", field)
+	}
+	return nil
+}
+
+func validateCache(cache CacheConfig, field string) error {
+	switch cache.Type {
+	case CacheLocal:
+		if cache.Path == "" {
+			return fmt.Errorf("%s.path is required for local caches", field)
+		}
+	case CacheRegistry:
+		if cache.Ref == "" {
+			return fmt.Errorf("%s.ref is required for registry caches", field)
+		}
+	case CacheInline:
+	default:
+		return fmt.Errorf("%s.type must be local, inline or registry", field)
+	}
+	switch cache.Mode {
+	case "", "min", "max":
+	default:
+		return fmt.Errorf("%s.mode must be min or max", field)
 	}
+	return nil
+}
 
+func validateVolumes(field string, mounts []string) error {
+	for _, mount := range mounts {
+		source, _, ok := strings.Cut(mount, ":")
+		if !ok || source == "" {
+			return fmt.Errorf("%s %q must be source:target", field, mount)
+		}
+	}
 	return nil
 }
+
+func IsNamedVolume(source string) bool {
+	return !strings.HasPrefix(source, "/") && !strings.HasPrefix(source, ".") && !strings.HasPrefix(source, "~")
+}
+
+func validOwner(owner string) bool {
+	for _, id := range strings.SplitN(owner, ":", 2) {
+		if _, err := strconv.ParseUint(id, 10, 32); err != nil {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,25 @@
+package config
+
+import "testing"
+
+func TestRetentionKeepLast(t *testing.T) {
+	base := "images:\n  app: {}\ndeploy:\n  type: ssh\n  containers:\n    - name: app\n      image: app\n  ssh:\n    host: example.com\n"
+	tests := []struct {
+		name      string
+		retention string
+		want      int
+	}{
+		{"default", "", DefaultKeepLast},
+		{"explicit zero", "retention:\n  keep_last: 0\n", 0},
+		{"explicit value", "retention:\n  keep_last: 2\n", 2},
+	}
+	for _, tt := range tests {
+		cfg, err := Parse([]byte(base + tt.retention))
+		if err != nil {
+			t.Fatalf("%s: Parse() error = %v", tt.name, err)
+		}
+		if got := *cfg.Retention.KeepLast; got != tt.want {
+			t.Errorf("%s: keep_last = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
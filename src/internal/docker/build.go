@@ -2,14 +2,23 @@ package docker
 
 import (
 	"fmt"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 
 	"bypirob/airo/src/internal/config"
 )
 
-func BuildImage(cfg config.Config, projectPath, tag, contextPath string) error {
+type BuildOptions struct {
+	NoCache bool
+	Pull    bool
+}
+
+func BuildImage(cfg config.Config, projectPath, tag, contextPath string, opts BuildOptions) error {
 	if contextPath == "" {
 		contextPath = "."
 	}
@@ -23,28 +32,288 @@ func BuildImage(cfg config.Config, projectPath, tag, contextPath string) error {
 	}
 
 	dockerfilePath := filepath.Join(projectPath, "Dockerfile")
+	generate := !HasDockerfile(projectPath)
 	if !filepath.IsAbs(contextPath) {
 		contextPath = filepath.Join(projectPath, contextPath)
 	}
 
-	for name, image := range cfg.Images {
+	hostPlatform := sync.OnceValue(func() string {
+		platform, err := RemotePlatform(cfg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		return platform
+	})
+
+	remote := cfg.Build.Mode == config.BuildModeRemote
+	remoteContext := ""
+	if remote {
+		if remoteContext, err = ensureRemoteBuilder(cfg); err != nil {
+			return err
+		}
+	}
+
+	containerBuilder := ""
+	for _, name := range cfg.ImageNames() {
+		image := cfg.Images[name]
 		imageTag := tags[name]
-		args := []string{
-			"buildx", "build",
-			"--platform", image.TargetArch,
-			"--tag", imageTag,
-			"--file", dockerfilePath,
-			contextPath,
+		target := buildTarget{
+			Context:   remoteContext,
+			Platforms: buildPlatforms(cfg, name, hostPlatform),
+			File:      dockerfilePath,
+		}
+		target.Push = cfg.Deploy.Type == "registry" && len(target.Platforms) > 1
+		if target.Push || (!remote && image.ExportsCache()) {
+			if containerBuilder == "" {
+				if containerBuilder, err = ensureContainerBuilder(); err != nil {
+					return err
+				}
+			}
+			target.Builder = containerBuilder
+		}
+
+		generated := ""
+		if generate {
+			stack, content, err := GenerateDockerfile(cfg, projectPath, name)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("No Dockerfile found, building %s with a generated %s Dockerfile\n", name, stack)
+			target.File, generated = "-", content
+		}
+
+		args, err := buildArgs(cfg, projectPath, contextPath, name, imageTag, target, opts)
+		if err != nil {
+			return err
 		}
 
 		cmd := exec.Command("docker", args...)
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
+		if generate {
+			cmd.Stdin = strings.NewReader(generated)
+		}
 
 		if err := cmd.Run(); err != nil {
 			return fmt.Errorf("docker buildx build (%s): %w", name, err)
 		}
+		switch {
+		case image.SBOM && remote:
+			fmt.Fprintf(os.Stderr, "Warning: skipping SBOM for %s, remote builds are loaded on the server\n", name)
+		case image.SBOM:
+			if _, err := writeSBOM(cfg, projectPath, name, imageTag); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
 }
+
+type buildTarget struct {
+	Context   string
+	Builder   string
+	Platforms []string
+	File      string
+	Push      bool
+}
+
+func buildArgs(cfg config.Config, projectPath, contextPath, name, imageTag string, target buildTarget, opts BuildOptions) ([]string, error) {
+	image := cfg.Images[name]
+	args := []string{}
+	if target.Context != "" {
+		args = append(args, "--context", target.Context)
+	}
+	args = append(args, "buildx", "build")
+	if target.Builder != "" {
+		args = append(args, "--builder", target.Builder)
+	}
+	args = append(args,
+		"--platform", strings.Join(target.Platforms, ","),
+		"--file", target.File,
+	)
+	if target.Push {
+		args = append(args, "--tag", registryRef(cfg, name, tagSuffix(imageTag)), "--push")
+	} else {
+		args = append(args, "--tag", imageTag, "--load")
+	}
+	if opts.NoCache {
+		args = append(args, "--no-cache")
+	}
+	if opts.Pull {
+		args = append(args, "--pull")
+	}
+	for _, cache := range image.CacheFrom {
+		args = append(args, "--cache-from", cacheSpec(projectPath, cache, false))
+	}
+	for _, cache := range image.CacheTo {
+		args = append(args, "--cache-to", cacheSpec(projectPath, cache, true))
+	}
+	secretArgs, err := buildSecretArgs(projectPath, image)
+	if err != nil {
+		return nil, err
+	}
+	args = append(args, secretArgs...)
+	labels := buildLabels(cfg, projectPath, name, imageTag)
+	for _, key := range slices.Sorted(maps.Keys(labels)) {
+		args = append(args, "--label", fmt.Sprintf("%s=%s", key, labels[key]))
+	}
+	return append(args, contextPath), nil
+}
+
+const containerBuilderName = "airo-builder"
+
+func PushesOnBuild(cfg config.Config) bool {
+	for _, name := range cfg.ImageNames() {
+		if pushedOnBuild(cfg, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func pushedOnBuild(cfg config.Config, name string) bool {
+	return cfg.Deploy.Type == "registry" && len(cfg.Images[name].TargetArch) > 1
+}
+
+func ensureContainerBuilder() (string, error) {
+	output, err := exec.Command("docker", "buildx", "inspect").Output()
+	if err != nil {
+		return "", fmt.Errorf("docker buildx inspect: %w", err)
+	}
+	name, driver := "", ""
+	for _, line := range strings.Split(string(output), "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case "Name":
+			if name == "" {
+				name = strings.TrimSpace(value)
+			}
+		case "Driver":
+			driver = strings.TrimSpace(value)
+		}
+	}
+	if driver != "" && driver != "docker" {
+		return name, nil
+	}
+
+	if exec.Command("docker", "buildx", "inspect", containerBuilderName).Run() == nil {
+		return containerBuilderName, nil
+	}
+	fmt.Printf("Creating buildx builder %s for multi-platform builds and cache export\n", containerBuilderName)
+	cmd := exec.Command("docker", "buildx", "create", "--name", containerBuilderName, "--driver", "docker-container")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("docker buildx create (%s): %w; multi-platform builds and cache export need a docker-container builder, see https://docs.docker.com/build/builders/drivers/docker-container/", containerBuilderName, err)
+	}
+	return containerBuilderName, nil
+}
+
+func cacheSpec(projectPath string, cache config.CacheConfig, export bool) string {
+	parts := []string{"type=" + cache.Type}
+	switch cache.Type {
+	case config.CacheLocal:
+		path := expandUserPath(cache.Path)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(projectPath, path)
+		}
+		if export {
+			parts = append(parts, "dest="+path)
+		} else {
+			parts = append(parts, "src="+path)
+		}
+	case config.CacheRegistry:
+		parts = append(parts, "ref="+cache.Ref)
+	}
+	if export && cache.Type != config.CacheInline && cache.Mode != "" {
+		parts = append(parts, "mode="+cache.Mode)
+	}
+	return strings.Join(parts, ",")
+}
+
+func buildSecretArgs(projectPath string, image config.ImageConfig) ([]string, error) {
+	args := []string{}
+	for _, secret := range image.Secrets {
+		if secret.Env != "" {
+			if _, ok := os.LookupEnv(secret.Env); !ok {
+				return nil, fmt.Errorf("build secret (%s): environment variable %s is not set", secret.ID, secret.Env)
+			}
+			args = append(args, "--secret", fmt.Sprintf("id=%s,env=%s", secret.ID, secret.Env))
+			continue
+		}
+
+		path := expandUserPath(secret.File)
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(projectPath, path)
+		}
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("build secret (%s): %w", secret.ID, err)
+		}
+		args = append(args, "--secret", fmt.Sprintf("id=%s,src=%s", secret.ID, path))
+	}
+
+	for _, ssh := range image.SSH {
+		id, paths, ok := strings.Cut(ssh, "=")
+		if ok {
+			expanded := []string{}
+			for _, path := range strings.Split(paths, ",") {
+				expanded = append(expanded, expandUserPath(path))
+			}
+			ssh = id + "=" + strings.Join(expanded, ",")
+		}
+		args = append(args, "--ssh", ssh)
+	}
+	return args, nil
+}
+
+func buildPlatforms(cfg config.Config, name string, hostPlatform func() string) []string {
+	platforms := cfg.Images[name].TargetArch
+	if cfg.Deploy.Type != "ssh" || len(platforms) == 1 {
+		if len(platforms) == 0 {
+			return []string{config.DefaultTargetArch}
+		}
+		return platforms
+	}
+
+	host := hostPlatform()
+	switch {
+	case len(platforms) == 0 && host != "":
+		return []string{host}
+	case len(platforms) == 0:
+		return []string{config.DefaultTargetArch}
+	case host != "" && slices.Contains(platforms, host):
+		return []string{host}
+	case host != "":
+		fmt.Fprintf(os.Stderr, "Warning: building %s for %s, but %s runs %s\n", name, platforms[0], cfg.Deploy.SSH.Host, host)
+	}
+	return platforms[:1]
+}
+
+func RemotePlatform(cfg config.Config) (string, error) {
+	output, err := sshCommand(cfg, "uname", "-m").Output()
+	if err != nil {
+		return "", fmt.Errorf("ssh detect architecture: %w", err)
+	}
+
+	machine := strings.TrimSpace(string(output))
+	switch machine {
+	case "x86_64", "amd64":
+		return "linux/amd64", nil
+	case "aarch64", "arm64":
+		return "linux/arm64", nil
+	case "armv7l":
+		return "linux/arm/v7", nil
+	case "armv6l":
+		return "linux/arm/v6", nil
+	case "i386", "i686":
+		return "linux/386", nil
+	case "ppc64le", "s390x", "riscv64":
+		return "linux/" + machine, nil
+	default:
+		return "", fmt.Errorf("unknown server architecture %q", machine)
+	}
+}
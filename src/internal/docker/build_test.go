@@ -0,0 +1,64 @@
+package docker
+
+import (
+	"slices"
+	"testing"
+
+	"bypirob/airo/src/internal/config"
+)
+
+func TestBuildArgsRemote(t *testing.T) {
+	cfg := config.Config{
+		Images: map[string]config.ImageConfig{"app": {TargetArch: []string{"linux/amd64"}}},
+		Deploy: config.DeployConfig{Type: "ssh", SSH: config.SSHConfig{Host: "example.com"}},
+		Build:  config.BuildConfig{Mode: config.BuildModeRemote},
+	}
+	target := buildTarget{Context: "airo-example-com", Platforms: []string{"linux/amd64"}, File: "Dockerfile"}
+
+	args, err := buildArgs(cfg, t.TempDir(), ".", "app", "app:1", target, BuildOptions{})
+	if err != nil {
+		t.Fatalf("buildArgs() error = %v", err)
+	}
+	want := []string{"--context", "airo-example-com", "buildx", "build", "--platform", "linux/amd64", "--file", "Dockerfile", "--tag", "app:1", "--load"}
+	if len(args) < len(want) || !slices.Equal(args[:len(want)], want) {
+		t.Errorf("buildArgs() = %q, want prefix %q", args, want)
+	}
+	if slices.Contains(args, "--builder") || slices.Contains(args, "--push") {
+		t.Errorf("buildArgs() = %q, remote builds must load through the server context", args)
+	}
+	if args[len(args)-1] != "." {
+		t.Errorf("buildArgs() last arg = %q, want build context", args[len(args)-1])
+	}
+}
+
+func TestBuildPlatforms(t *testing.T) {
+	tests := []struct {
+		name       string
+		deployType string
+		targetArch config.Platforms
+		host       string
+		want       []string
+		probed     bool
+	}{
+		{"registry default", "registry", nil, "", []string{config.DefaultTargetArch}, false},
+		{"registry list", "registry", config.Platforms{"linux/amd64", "linux/arm64"}, "", []string{"linux/amd64", "linux/arm64"}, false},
+		{"ssh single platform", "ssh", config.Platforms{"linux/arm64"}, "linux/amd64", []string{"linux/arm64"}, false},
+		{"ssh unset", "ssh", nil, "linux/arm64", []string{"linux/arm64"}, true},
+		{"ssh unset without probe", "ssh", nil, "", []string{config.DefaultTargetArch}, true},
+		{"ssh list", "ssh", config.Platforms{"linux/amd64", "linux/arm64"}, "linux/arm64", []string{"linux/arm64"}, true},
+	}
+	for _, tt := range tests {
+		cfg := config.Config{
+			Images: map[string]config.ImageConfig{"app": {TargetArch: tt.targetArch}},
+			Deploy: config.DeployConfig{Type: tt.deployType},
+		}
+		probed := false
+		got := buildPlatforms(cfg, "app", func() string {
+			probed = true
+			return tt.host
+		})
+		if !slices.Equal(got, tt.want) || probed != tt.probed {
+			t.Errorf("%s: buildPlatforms() = %v (probed %v), want %v (probed %v)", tt.name, got, probed, tt.want, tt.probed)
+		}
+	}
+}
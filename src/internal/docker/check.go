@@ -0,0 +1,45 @@
+package docker
+
+import (
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"bypirob/airo/src/internal/config"
+)
+
+func sshProbe(cfg config.Config, remoteArgs ...string) *exec.Cmd {
+	cmd := sshCommand(cfg, remoteArgs...)
+	cmd.Args = slices.Insert(cmd.Args, 1, "-o", "BatchMode=yes", "-o", "ConnectTimeout=10")
+	return cmd
+}
+
+func probeOutput(cmd *exec.Cmd) (string, error) {
+	output, err := cmd.CombinedOutput()
+	text := strings.TrimSpace(string(output))
+	if err != nil && text != "" {
+		return text, fmt.Errorf("%w: %s", err, lastLine(text))
+	}
+	return text, err
+}
+
+func lastLine(text string) string {
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
+
+func CheckSSH(cfg config.Config) error {
+	if _, err := probeOutput(sshProbe(cfg, "true")); err != nil {
+		return fmt.Errorf("ssh connect (%s): %w", cfg.Deploy.SSH.Host, err)
+	}
+	return nil
+}
+
+func ServerDockerVersion(cfg config.Config) (string, error) {
+	output, err := probeOutput(sshProbe(cfg, "docker", "version", "--format", shellQuote("{{.Server.Version}}")))
+	if err != nil {
+		return "", fmt.Errorf("ssh docker version (%s): %w", cfg.Deploy.SSH.Host, err)
+	}
+	return output, nil
+}
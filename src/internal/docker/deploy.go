@@ -3,46 +3,211 @@ package docker
 import (
 	"fmt"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"bypirob/airo/src/internal/config"
 )
 
-func Deploy(cfg config.Config, tag string) error {
-	tags, err := resolveTags(cfg, "", tag)
+const (
+	dependencyTimeout      = 5 * time.Minute
+	dependencyPollInterval = 2 * time.Second
+)
+
+func Deploy(cfg config.Config, projectPath, tag string) error {
+	if projectPath == "" {
+		projectPath = "."
+	}
+
+	tags, err := resolveTags(cfg, projectPath, tag)
 	if err != nil {
 		return err
 	}
 
-	for _, container := range cfg.Deploy.Containers {
-		imageTag := tags[container.Image]
-		runArgs := []string{"docker", "run", "-d", "--name", container.Name}
-		if container.Port != 0 && container.AppPort != 0 {
-			runArgs = append(runArgs, "-p", fmt.Sprintf("%d:%d", container.Port, container.AppPort))
+	containers, err := config.DeployOrder(cfg.Deploy.Containers)
+	if err != nil {
+		return err
+	}
+
+	if err := ensureResources(cfg, containers); err != nil {
+		return err
+	}
+	if usesProxy(containers) {
+		if err := ensureProxyNetwork(cfg); err != nil {
+			return err
 		}
-		if container.EnvFile != "" {
-			runArgs = append(runArgs, "--env-file", container.EnvFile)
+	}
+
+	if err := runJobs(cfg, tags, config.PhaseBeforeDeploy); err != nil {
+		return err
+	}
+
+	for _, container := range containers {
+		for _, dependency := range container.DependsOn {
+			if err := waitForDependency(cfg, dependency); err != nil {
+				return fmt.Errorf("wait for %s (%s): %w", dependency.Name, container.Name, err)
+			}
 		}
-		for _, network := range container.Networks {
-			if network == "" {
-				continue
+
+		if hasSecrets(container) {
+			if err := deliverSecrets(cfg, projectPath, container); err != nil {
+				return err
 			}
-			runArgs = append(runArgs, "--network", network)
 		}
-		runArgs = append(runArgs, imageTag)
+		if err := runContainer(cfg, container, tags[container.Image]); err != nil {
+			return err
+		}
+	}
 
-		stopCmd := shellJoin([]string{"docker", "stop", container.Name})
-		removeCmd := shellJoin([]string{"docker", "rm", "-f", container.Name})
-		runCmd := shellJoin(runArgs)
-		remoteCmd := fmt.Sprintf("%s >/dev/null 2>&1 || true; %s >/dev/null 2>&1 || true; %s", stopCmd, removeCmd, runCmd)
+	if err := updateProxy(cfg, containers); err != nil {
+		return err
+	}
 
-		sshCmd := sshCommand(cfg, "sh", "-c", remoteCmd)
-		sshCmd.Stdout = os.Stdout
-		sshCmd.Stderr = os.Stderr
+	return runJobs(cfg, tags, config.PhaseAfterDeploy)
+}
 
-		if err := sshCmd.Run(); err != nil {
-			return fmt.Errorf("ssh deploy (%s): %w", container.Name, err)
-		}
+func runContainer(cfg config.Config, container config.ContainerConfig, imageTag string) error {
+	runArgs := []string{"--name", container.Name}
+	if container.Port != 0 && container.AppPort != 0 {
+		runArgs = append(runArgs, "-p", fmt.Sprintf("%d:%d", container.Port, container.AppPort))
+	}
+	if container.EnvFile != "" {
+		runArgs = append(runArgs, "--env-file", container.EnvFile)
+	}
+	for _, volume := range container.Volumes {
+		runArgs = append(runArgs, "-v", volume)
+	}
+	secretArgs, err := secretRunArgs(cfg, container)
+	if err != nil {
+		return err
 	}
+	runArgs = append(runArgs, secretArgs...)
+
+	stopCmd := shellJoin([]string{"docker", "stop", container.Name})
+	removeCmd := shellJoin([]string{"docker", "rm", "-f", container.Name})
+	runCmd := dockerRunScript(runArgs, containerNetworks(cfg, container), container.Name, []string{imageTag}, false)
+	remoteCmd := fmt.Sprintf("%s >/dev/null 2>&1 || true; %s >/dev/null 2>&1 || true; %s", stopCmd, removeCmd, runCmd)
+
+	sshCmd := sshCommand(cfg, "sh", "-c", remoteCmd)
+	sshCmd.Stdout = os.Stdout
+	sshCmd.Stderr = os.Stderr
 
+	if err := sshCmd.Run(); err != nil {
+		return fmt.Errorf("ssh deploy (%s): %w", container.Name, err)
+	}
 	return nil
 }
+
+func dockerRunScript(runArgs, networks []string, name string, image []string, attach bool) string {
+	if len(networks) > 0 {
+		runArgs = append(slices.Clone(runArgs), "--network", networks[0])
+	}
+	if len(networks) < 2 {
+		run := []string{"docker", "run"}
+		if !attach {
+			run = append(run, "-d")
+		}
+		return shellJoin(slices.Concat(run, runArgs, image))
+	}
+
+	steps := []string{shellJoin(slices.Concat([]string{"docker", "create"}, runArgs, image)) + " >/dev/null"}
+	for _, network := range networks[1:] {
+		steps = append(steps, shellJoin([]string{"docker", "network", "connect", network, name}))
+	}
+	start := []string{"docker", "start"}
+	if attach {
+		start = append(start, "-a")
+	}
+	return fmt.Sprintf("%s || { %s >/dev/null 2>&1; exit 1; }; %s",
+		strings.Join(steps, " && "), shellJoin([]string{"docker", "rm", "-f", name}), shellJoin(append(start, name)))
+}
+
+func containerNetworks(cfg config.Config, container config.ContainerConfig) []string {
+	networks := make([]string, 0, len(container.Networks)+1)
+	for _, network := range container.Networks {
+		if network == "" || slices.Contains(networks, network) {
+			continue
+		}
+		networks = append(networks, network)
+	}
+	if len(container.Domains) > 0 && !slices.Contains(networks, cfg.Deploy.Proxy.Network) {
+		networks = append(networks, cfg.Deploy.Proxy.Network)
+	}
+	return networks
+}
+
+func waitForDependency(cfg config.Config, dependency config.DependencyConfig) error {
+	deadline := time.Now().Add(dependencyTimeout)
+	for {
+		state, err := containerState(cfg, dependency.Name)
+		if err != nil {
+			return err
+		}
+
+		switch dependency.Condition {
+		case config.ConditionHealthy:
+			if state.Health == "healthy" {
+				return nil
+			}
+			if state.Health == "" && state.Status != "" {
+				return fmt.Errorf("container has no healthcheck")
+			}
+			if state.Health == "unhealthy" {
+				return fmt.Errorf("container is unhealthy")
+			}
+		case config.ConditionCompleted:
+			if state.Status == "exited" || state.Status == "dead" {
+				if state.ExitCode != 0 {
+					return fmt.Errorf("container exited with code %d", state.ExitCode)
+				}
+				return nil
+			}
+		default:
+			if state.Status == "running" || state.Status == "exited" {
+				return nil
+			}
+		}
+
+		if time.Now().After(deadline) {
+			if state.Status == "" {
+				return fmt.Errorf("timed out after %s: container not found", dependencyTimeout)
+			}
+			return fmt.Errorf("timed out after %s: container is %s", dependencyTimeout, state.Status)
+		}
+		time.Sleep(dependencyPollInterval)
+	}
+}
+
+type containerStateResult struct {
+	Status   string
+	ExitCode int
+	Health   string
+}
+
+func containerState(cfg config.Config, name string) (containerStateResult, error) {
+	format := "{{.State.Status}} {{.State.ExitCode}} {{if .State.Health}}{{.State.Health.Status}}{{end}}"
+	remoteCmd := fmt.Sprintf("%s 2>/dev/null || true", shellJoin([]string{"docker", "inspect", "--format", format, name}))
+
+	output, err := sshShell(cfg, remoteCmd).Output()
+	if err != nil {
+		return containerStateResult{}, fmt.Errorf("ssh inspect: %w", err)
+	}
+
+	fields := strings.Fields(string(output))
+	if len(fields) < 2 {
+		return containerStateResult{}, nil
+	}
+
+	exitCode, err := strconv.Atoi(fields[1])
+	if err != nil {
+		return containerStateResult{}, fmt.Errorf("parse exit code %q: %w", fields[1], err)
+	}
+
+	state := containerStateResult{Status: fields[0], ExitCode: exitCode}
+	if len(fields) > 2 {
+		state.Health = fields[2]
+	}
+	return state, nil
+}
@@ -0,0 +1,36 @@
+package docker
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDockerRunScript(t *testing.T) {
+	tests := []struct {
+		name     string
+		networks []string
+		attach   bool
+		want     string
+	}{
+		{"no network", nil, false, "docker run -d --name app app:1"},
+		{"one network", []string{"backend"}, false, "docker run -d --name app --network backend app:1"},
+		{
+			"extra networks are connected before start",
+			[]string{"backend", "airo"},
+			false,
+			"docker create --name app --network backend app:1 >/dev/null && docker network connect airo app || { docker rm -f app >/dev/null 2>&1; exit 1; }; docker start app",
+		},
+		{
+			"attached",
+			[]string{"backend", "airo"},
+			true,
+			"docker create --name app --network backend app:1 >/dev/null && docker network connect airo app || { docker rm -f app >/dev/null 2>&1; exit 1; }; docker start -a app",
+		},
+	}
+	for _, tt := range tests {
+		got := dockerRunScript([]string{"--name", "app"}, tt.networks, "app", []string{"app:1"}, tt.attach)
+		if got = strings.ReplaceAll(got, "'", ""); got != tt.want {
+			t.Errorf("%s: dockerRunScript() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package docker
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	"bypirob/airo/src/internal/config"
+	"bypirob/airo/src/internal/dockerfile"
+)
+
+func HasDockerfile(projectPath string) bool {
+	_, err := os.Stat(filepath.Join(projectPath, "Dockerfile"))
+	return !errors.Is(err, os.ErrNotExist)
+}
+
+func GenerateDockerfile(cfg config.Config, projectPath, name string) (dockerfile.Stack, string, error) {
+	stack, err := dockerfile.Detect(projectPath)
+	if err != nil {
+		return dockerfile.Stack{}, "", err
+	}
+
+	baseImage := cfg.Images[name].BaseImage
+	if baseImage == config.DefaultBaseImage && stack.Name != dockerfile.StackNode {
+		baseImage = ""
+	}
+	port := 0
+	for _, container := range cfg.Deploy.Containers {
+		if container.Image == name && container.AppPort != 0 {
+			port = container.AppPort
+			break
+		}
+	}
+	return stack, stack.Dockerfile(baseImage, cfg.Images[name].RuntimeImage, port), nil
+}
@@ -0,0 +1,316 @@
+package docker
+
+import (
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"bypirob/airo/src/internal/config"
+)
+
+const (
+	CheckOK   = "ok"
+	CheckWarn = "warn"
+	CheckFail = "fail"
+)
+
+const (
+	minDiskSpaceKB  = 1 << 20
+	lowDiskSpaceKB  = 5 << 20
+	remoteDockerDir = "/var/lib/docker"
+)
+
+type Check struct {
+	Name   string
+	Status string
+	Detail string
+	Hint   string
+}
+
+func passed(name, detail string) Check {
+	return Check{Name: name, Status: CheckOK, Detail: detail}
+}
+
+func warned(name, detail, hint string) Check {
+	return Check{Name: name, Status: CheckWarn, Detail: detail, Hint: hint}
+}
+
+func failed(name, detail, hint string) Check {
+	return Check{Name: name, Status: CheckFail, Detail: detail, Hint: hint}
+}
+
+func Doctor(cfg config.Config, projectPath string) []Check {
+	if projectPath == "" {
+		projectPath = "."
+	}
+
+	checks := []Check{checkLocalDocker(), checkBuildx(), checkGit(projectPath)}
+	checks = append(checks, checkSecretFiles(cfg, projectPath)...)
+	if cfg.Deploy.Registry.RegistryURL != "" {
+		checks = append(checks, checkRegistry(cfg))
+	}
+	if cfg.Deploy.SSH.Host == "" {
+		return checks
+	}
+
+	sshCheck := checkSSH(cfg)
+	checks = append(checks, sshCheck)
+	if sshCheck.Status == CheckFail {
+		return checks
+	}
+	dockerCheck := checkRemoteDocker(cfg)
+	checks = append(checks, dockerCheck, checkDiskSpace(cfg))
+	if dockerCheck.Status == CheckFail {
+		return checks
+	}
+	checks = append(checks, checkNetworks(cfg)...)
+	checks = append(checks, checkEnvFiles(cfg)...)
+	return append(checks, checkPorts(cfg)...)
+}
+
+func checkLocalDocker() Check {
+	output, err := probeOutput(exec.Command("docker", "version", "--format", "{{.Server.Version}}"))
+	if err != nil {
+		if _, lookErr := exec.LookPath("docker"); lookErr != nil {
+			return failed("local docker", "docker is not installed", "Install Docker Desktop or Docker Engine: https://docs.docker.com/get-docker/")
+		}
+		return failed("local docker", err.Error(), "Start Docker Desktop or the docker service, and make sure your user can access the daemon.")
+	}
+	return passed("local docker", "Docker "+output)
+}
+
+func checkBuildx() Check {
+	if _, err := exec.LookPath("docker"); err != nil {
+		return failed("buildx", "docker is not installed", "Install Docker first; buildx ships with Docker Desktop and the docker-buildx-plugin package.")
+	}
+	output, err := probeOutput(exec.Command("docker", "buildx", "version"))
+	if err != nil {
+		return failed("buildx", err.Error(), "Install the buildx plugin: https://docs.docker.com/go/buildx/")
+	}
+	return passed("buildx", output)
+}
+
+func checkGit(projectPath string) Check {
+	if _, err := exec.LookPath("git"); err != nil {
+		return failed("git", "git is not installed", "Install git; airo uses it for tags, labels and release checks.")
+	}
+	cmd := exec.Command("git", "rev-parse", "--is-inside-work-tree")
+	cmd.Dir = projectPath
+	if _, err := probeOutput(cmd); err != nil {
+		return warned("git", "the project is not a git repository", "Run `git init` and commit; tags, image labels and release checks read the git state.")
+	}
+	return passed("git", "repository found")
+}
+
+func checkSecretFiles(cfg config.Config, projectPath string) []Check {
+	checks := []Check{}
+	for _, container := range cfg.Deploy.Containers {
+		sources := []string{}
+		if container.Secrets.EnvFile != "" {
+			sources = append(sources, container.Secrets.EnvFile)
+		}
+		for _, secret := range container.Secrets.Files {
+			sources = append(sources, secret.Source)
+		}
+		for _, source := range sources {
+			path := source
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(projectPath, path)
+			}
+			name := fmt.Sprintf("secret %s (%s)", source, container.Name)
+			if _, err := os.Stat(path); err != nil {
+				checks = append(checks, failed(name, "not found locally", "Create it with `airo secrets edit "+source+"`."))
+				continue
+			}
+			checks = append(checks, passed(name, "found"))
+		}
+	}
+	return checks
+}
+
+func checkRegistry(cfg config.Config) Check {
+	base, err := registryBase(cfg)
+	if err != nil {
+		return failed("registry", err.Error(), "Set deploy.registry.registry_url.")
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(base + "/v2/")
+	if err != nil {
+		return failed("registry", err.Error(), "Check deploy.registry.registry_url and that the registry is reachable from this machine.")
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusOK, resp.StatusCode == http.StatusUnauthorized:
+		return passed("registry", fmt.Sprintf("%s responded %d", base, resp.StatusCode))
+	default:
+		return failed("registry", fmt.Sprintf("%s/v2/ responded %s", base, resp.Status), "Check that deploy.registry.registry_url points to a Docker registry.")
+	}
+}
+
+func checkSSH(cfg config.Config) Check {
+	err := CheckSSH(cfg)
+	if err == nil {
+		return passed("ssh", "connected to "+cfg.Deploy.SSH.Host)
+	}
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "Permission denied"):
+		return failed("ssh", message, "Check deploy.ssh.user and deploy.ssh.identity_file, and that the public key is in ~/.ssh/authorized_keys on the server (ssh-copy-id).")
+	case strings.Contains(message, "Could not resolve"):
+		return failed("ssh", message, "Check deploy.ssh.host; the name does not resolve.")
+	case strings.Contains(message, "Host key verification failed"):
+		return failed("ssh", message, "Connect once with ssh to accept the host key, or update ~/.ssh/known_hosts if the server was rebuilt.")
+	default:
+		return failed("ssh", message, "Check that the server is up, deploy.ssh.host and deploy.ssh.port are correct, and the firewall allows ssh.")
+	}
+}
+
+func checkRemoteDocker(cfg config.Config) Check {
+	version, err := ServerDockerVersion(cfg)
+	if err == nil {
+		return passed("server docker", "Docker "+version)
+	}
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "not found"):
+		return failed("server docker", message, "Run `airo server setup` to install Docker.")
+	case strings.Contains(message, "permission denied"):
+		return failed("server docker", message, fmt.Sprintf("Add the user to the docker group: sudo usermod -aG docker %s (or run `airo server setup`), then reconnect.", orUser(cfg.Deploy.SSH.User)))
+	case strings.Contains(message, "Cannot connect to the Docker daemon"):
+		return failed("server docker", message, "Start the daemon on the server: sudo systemctl enable --now docker")
+	default:
+		return failed("server docker", message, "Run `docker version` on the server to see why the daemon is not usable.")
+	}
+}
+
+func checkDiskSpace(cfg config.Config) Check {
+	script := fmt.Sprintf("df -Pk %s 2>/dev/null || df -Pk /", remoteDockerDir)
+	output, err := probeOutput(sshProbe(cfg, "sh", "-c", shellQuote(script)))
+	if err != nil {
+		return warned("disk space", err.Error(), "Run `df -h` on the server to check free space.")
+	}
+	fields := strings.Fields(lastLine(output))
+	if len(fields) < 4 {
+		return warned("disk space", "could not parse df output", "Run `df -h` on the server to check free space.")
+	}
+	available, err := strconv.ParseInt(fields[3], 10, 64)
+	if err != nil {
+		return warned("disk space", "could not parse df output", "Run `df -h` on the server to check free space.")
+	}
+
+	detail := fmt.Sprintf("%.1f GB free", float64(available)/(1<<20))
+	switch {
+	case available < minDiskSpaceKB:
+		return failed("disk space", detail, "Free space with `airo prune` or `docker system prune` on the server.")
+	case available < lowDiskSpaceKB:
+		return warned("disk space", detail, "Free space with `airo prune` before it runs out.")
+	default:
+		return passed("disk space", detail)
+	}
+}
+
+func checkNetworks(cfg config.Config) []Check {
+	networks, _ := referencedResources(cfg, cfg.Deploy.Containers, cfg.Jobs)
+	if usesProxy(cfg.Deploy.Containers) {
+		networks = append(networks, cfg.Deploy.Proxy.Network)
+	}
+
+	checks := []Check{}
+	for _, network := range networks {
+		name := "network " + network
+		if _, err := probeOutput(sshProbe(cfg, "docker", "network", "inspect", shellQuote(network))); err != nil {
+			checks = append(checks, warned(name, "missing on the server", "It is created on the next deploy; create it now with `docker network create "+network+"`."))
+			continue
+		}
+		checks = append(checks, passed(name, "exists"))
+	}
+	return checks
+}
+
+func checkEnvFiles(cfg config.Config) []Check {
+	checks := []Check{}
+	check := func(owner, path, hint string) {
+		name := fmt.Sprintf("env file %s (%s)", path, owner)
+		if _, err := probeOutput(sshProbe(cfg, "test", "-r", shellQuote(path))); err != nil {
+			checks = append(checks, failed(name, "missing or unreadable on the server",
+				fmt.Sprintf("%s, and make it readable by %s.", hint, orUser(cfg.Deploy.SSH.User))))
+			return
+		}
+		checks = append(checks, passed(name, "found"))
+	}
+	for _, container := range cfg.Deploy.Containers {
+		if container.EnvFile != "" {
+			check(container.Name, container.EnvFile,
+				fmt.Sprintf("Create it with `airo env push %s <file>` or `airo env set %s KEY=value`", container.Name, container.Name))
+		}
+	}
+	for _, job := range cfg.Jobs {
+		if job.EnvFile != "" {
+			check(job.Name, job.EnvFile, "Copy it to the server with `"+scpCommand(cfg, job.EnvFile)+"`")
+		}
+	}
+	return checks
+}
+
+func checkPorts(cfg config.Config) []Check {
+	owners := map[int]string{}
+	for _, container := range cfg.Deploy.Containers {
+		if container.Port != 0 {
+			owners[container.Port] = container.Name
+		}
+	}
+	if usesProxy(cfg.Deploy.Containers) {
+		owners[80] = cfg.Deploy.Proxy.Name
+		owners[443] = cfg.Deploy.Proxy.Name
+	}
+
+	checks := []Check{}
+	for _, port := range slices.Sorted(maps.Keys(owners)) {
+		owner := owners[port]
+		name := fmt.Sprintf("port %d (%s)", port, owner)
+		script := fmt.Sprintf("c=$(docker ps --filter publish=%d --format '{{.Names}}' | head -n 1); "+
+			"if [ -n \"$c\" ]; then echo \"container $c\"; "+
+			"elif command -v ss >/dev/null 2>&1 && ss -Hltn | awk '{print $4}' | grep -Eq '[:.]%d$'; then echo process; fi", port, port)
+		output, err := probeOutput(sshProbe(cfg, "sh", "-c", shellQuote(script)))
+		switch {
+		case err != nil:
+			checks = append(checks, warned(name, err.Error(), "Check the port manually with `ss -ltnp` on the server."))
+		case output == "process":
+			checks = append(checks, failed(name, "in use by another process on the server",
+				fmt.Sprintf("Stop the process listening on %d (see `sudo ss -ltnp`) or change the port in airo.yaml.", port)))
+		case strings.HasPrefix(output, "container ") && strings.TrimPrefix(output, "container ") != owner:
+			other := strings.TrimPrefix(output, "container ")
+			checks = append(checks, failed(name, "published by container "+other,
+				fmt.Sprintf("Stop it with `docker rm -f %s` on the server or change the port in airo.yaml.", other)))
+		default:
+			checks = append(checks, passed(name, "available"))
+		}
+	}
+	return checks
+}
+
+func scpCommand(cfg config.Config, path string) string {
+	destination := cfg.Deploy.SSH.Host
+	if cfg.Deploy.SSH.User != "" {
+		destination = cfg.Deploy.SSH.User + "@" + destination
+	}
+	command := "scp"
+	if cfg.Deploy.SSH.Port != 0 && cfg.Deploy.SSH.Port != 22 {
+		command += fmt.Sprintf(" -P %d", cfg.Deploy.SSH.Port)
+	}
+	return fmt.Sprintf("%s <file> %s:%s", command, destination, path)
+}
+
+func orUser(user string) string {
+	if user == "" {
+		return "$USER"
+	}
+	return user
+}
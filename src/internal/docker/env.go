@@ -0,0 +1,58 @@
+package docker
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strings"
+
+	"bypirob/airo/src/internal/config"
+)
+
+func ReadEnvFile(cfg config.Config, container config.ContainerConfig) (string, error) {
+	if container.EnvFile == "" {
+		return "", fmt.Errorf("deploy.containers.env_file is not set for %s", container.Name)
+	}
+
+	script := fmt.Sprintf("if [ -f %s ]; then cat %s; fi", shellQuote(container.EnvFile), shellQuote(container.EnvFile))
+	cmd := sshShell(cfg, script)
+	cmd.Stderr = os.Stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("ssh read env file (%s): %w", container.Name, err)
+	}
+	return string(output), nil
+}
+
+func WriteEnvFile(cfg config.Config, container config.ContainerConfig, contents string) error {
+	if container.EnvFile == "" {
+		return fmt.Errorf("deploy.containers.env_file is not set for %s", container.Name)
+	}
+
+	file := shellQuote(container.EnvFile)
+	tmp := shellQuote(container.EnvFile + ".airo-tmp")
+	script := fmt.Sprintf("set -e; umask 077; mkdir -p %s; cat > %s; chmod 600 %s; mv %s %s",
+		shellQuote(path.Dir(container.EnvFile)), tmp, tmp, tmp, file)
+
+	cmd := sshShell(cfg, script)
+	cmd.Stdin = strings.NewReader(contents)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ssh write env file (%s): %w", container.Name, err)
+	}
+	return nil
+}
+
+func RestartContainer(cfg config.Config, container config.ContainerConfig) error {
+	output, err := sshCommand(cfg, "docker", "inspect", "--format", "'{{.Config.Image}}'", shellQuote(container.Name)).Output()
+	if err != nil {
+		return fmt.Errorf("ssh inspect (%s): %w", container.Name, err)
+	}
+
+	imageTag := strings.TrimSpace(string(output))
+	if imageTag == "" {
+		return fmt.Errorf("container %s is not deployed", container.Name)
+	}
+	return runContainer(cfg, container, imageTag)
+}
@@ -0,0 +1,153 @@
+package docker
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+func gitShortSHA(projectPath string) (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--short", "HEAD")
+	cmd.Dir = projectPath
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("resolve git commit: %w (%s)", err, strings.TrimSpace(string(output)))
+	}
+
+	sha := strings.TrimSpace(string(output))
+	if sha == "" {
+		return "", fmt.Errorf("resolve git commit: empty sha")
+	}
+
+	return sha, nil
+}
+
+func gitBranch(projectPath string) string {
+	output, err := gitOutput(projectPath, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return output
+}
+
+func gitDescribe(projectPath string) (string, error) {
+	output, err := gitOutput(projectPath, "describe", "--tags", "--always")
+	if err != nil {
+		return "", fmt.Errorf("git describe: %w", err)
+	}
+	return output, nil
+}
+
+func gitExactTag(projectPath string) (string, error) {
+	output, err := gitOutput(projectPath, "describe", "--tags", "--exact-match", "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("HEAD has no git tag: %w", err)
+	}
+	return output, nil
+}
+
+type GitState struct {
+	SHA      string
+	Branch   string
+	Dirty    bool
+	Upstream string
+	Unpushed int
+}
+
+func ReadGitState(projectPath string) (GitState, error) {
+	if projectPath == "" {
+		projectPath = "."
+	}
+
+	sha, err := gitShortSHA(projectPath)
+	if err != nil {
+		return GitState{}, err
+	}
+	status, err := gitOutput(projectPath, "status", "--porcelain", "--untracked-files=no", "--", ":/", ":(exclude)"+localStateDir)
+	if err != nil {
+		return GitState{}, fmt.Errorf("git status: %w", err)
+	}
+
+	state := GitState{SHA: sha, Branch: gitBranch(projectPath), Dirty: status != ""}
+	if upstream, err := gitOutput(projectPath, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}"); err == nil {
+		state.Upstream = upstream
+		count, err := gitOutput(projectPath, "rev-list", "--count", "@{upstream}..HEAD")
+		if err != nil {
+			return GitState{}, fmt.Errorf("git rev-list: %w", err)
+		}
+		state.Unpushed, _ = strconv.Atoi(count)
+	}
+	return state, nil
+}
+
+func (s GitState) Problems() []string {
+	problems := make([]string, 0, 2)
+	if s.Dirty {
+		problems = append(problems, "working tree has uncommitted changes")
+	}
+	if s.Unpushed > 0 {
+		problems = append(problems, fmt.Sprintf("%d commits are not pushed to %s", s.Unpushed, s.Upstream))
+	}
+	return problems
+}
+
+func (s GitState) TagSuffix() string {
+	switch {
+	case s.Dirty:
+		return "-dirty"
+	case s.Unpushed > 0:
+		return "-unpushed"
+	default:
+		return ""
+	}
+}
+
+func (s GitState) Labels() map[string]string {
+	return map[string]string{
+		"airo.git.revision": s.SHA,
+		"airo.git.branch":   s.Branch,
+		"airo.git.dirty":    strconv.FormatBool(s.Dirty),
+		"airo.git.upstream": s.Upstream,
+		"airo.git.unpushed": strconv.Itoa(s.Unpushed),
+	}
+}
+
+func CommitsSince(projectPath, revision string) ([]string, error) {
+	if projectPath == "" {
+		projectPath = "."
+	}
+	if !IsCommitSHA(revision) {
+		return nil, fmt.Errorf("revision %q is not a commit sha", revision)
+	}
+	output, err := gitOutput(projectPath, "log", "--oneline", "--end-of-options", revision+"..HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("git log %s..HEAD: %w", revision, err)
+	}
+	if output == "" {
+		return nil, nil
+	}
+	return strings.Split(output, "\n"), nil
+}
+
+func IsCommitSHA(revision string) bool {
+	if len(revision) < 7 || len(revision) > 40 {
+		return false
+	}
+	for _, r := range revision {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+func gitOutput(projectPath string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = projectPath
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,25 @@
+package docker
+
+import "testing"
+
+func TestIsCommitSHA(t *testing.T) {
+	tests := []struct {
+		revision string
+		want     bool
+	}{
+		{"abc1234", true},
+		{"0123456789abcdef0123456789abcdef01234567", true},
+		{"abc123", false},
+		{"0123456789abcdef0123456789abcdef012345678", false},
+		{"ABC1234", false},
+		{"--output=/tmp/x", false},
+		{"-abc1234", false},
+		{"main", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := IsCommitSHA(tt.revision); got != tt.want {
+			t.Errorf("IsCommitSHA(%q) = %v, want %v", tt.revision, got, tt.want)
+		}
+	}
+}
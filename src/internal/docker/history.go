@@ -0,0 +1,110 @@
+package docker
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"bypirob/airo/src/internal/config"
+)
+
+const remoteHistoryFile = remoteStateDir + "/history.jsonl"
+
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+type HistoryRecord struct {
+	Time       time.Time         `json:"time"`
+	Command    string            `json:"command"`
+	User       string            `json:"user"`
+	GitSHA     string            `json:"git_sha,omitempty"`
+	GitBranch  string            `json:"git_branch,omitempty"`
+	Containers map[string]string `json:"containers"`
+	Outcome    string            `json:"outcome"`
+	Error      string            `json:"error,omitempty"`
+	Duration   float64           `json:"duration_seconds"`
+}
+
+func NewHistoryRecord(cfg config.Config, projectPath, command, tag string) (HistoryRecord, error) {
+	if projectPath == "" {
+		projectPath = "."
+	}
+
+	tags, err := resolveTags(cfg, projectPath, tag)
+	if err != nil {
+		return HistoryRecord{}, err
+	}
+
+	containers := make(map[string]string, len(cfg.Deploy.Containers))
+	for _, container := range cfg.Deploy.Containers {
+		containers[container.Name] = tags[container.Image]
+	}
+
+	sha, _ := gitShortSHA(projectPath)
+	return HistoryRecord{
+		Time:       time.Now().UTC(),
+		Command:    command,
+		User:       lockHolder(),
+		GitSHA:     sha,
+		GitBranch:  gitBranch(projectPath),
+		Containers: containers,
+	}, nil
+}
+
+func (r *HistoryRecord) Finish(err error) {
+	r.Duration = time.Since(r.Time).Round(time.Millisecond).Seconds()
+	r.Outcome = OutcomeSuccess
+	if err != nil {
+		r.Outcome = OutcomeFailure
+		r.Error = err.Error()
+	}
+}
+
+func AppendHistory(cfg config.Config, record HistoryRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("encode history record: %w", err)
+	}
+
+	script := fmt.Sprintf("mkdir -p %s && printf '%%s\\n' %s >> %s", remoteStateDir, shellQuote(string(line)), remoteHistoryFile)
+	if err := sshShell(cfg, script).Run(); err != nil {
+		return fmt.Errorf("ssh append history: %w", err)
+	}
+	return nil
+}
+
+func History(cfg config.Config, container string) ([]HistoryRecord, error) {
+	output, err := sshShell(cfg, fmt.Sprintf("cat %s 2>/dev/null || true", remoteHistoryFile)).Output()
+	if err != nil {
+		return nil, fmt.Errorf("ssh read history: %w", err)
+	}
+
+	records := make([]HistoryRecord, 0)
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var record HistoryRecord
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			return nil, fmt.Errorf("parse history record: %w", err)
+		}
+		if container != "" {
+			if _, ok := record.Containers[container]; !ok {
+				continue
+			}
+		}
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read history: %w", err)
+	}
+
+	return records, nil
+}
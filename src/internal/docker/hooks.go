@@ -0,0 +1,97 @@
+package docker
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+
+	"bypirob/airo/src/internal/config"
+)
+
+func RunHooks(cfg config.Config, projectPath, name, tag string, failure error) error {
+	hooks := cfg.Hooks.Get(name)
+	if len(hooks) == 0 {
+		return nil
+	}
+	if projectPath == "" {
+		projectPath = "."
+	}
+
+	tags, err := resolveTags(cfg, projectPath, tag)
+	if err != nil {
+		return err
+	}
+	vars := hookVars(cfg, name, tags, failure)
+
+	for _, hook := range hooks {
+		var cmd *exec.Cmd
+		if hook.Remote {
+			cmd = sshShell(cfg, hookExports(vars)+hook.Run)
+		} else {
+			cmd = exec.Command("sh", "-c", hook.Run)
+			cmd.Dir = projectPath
+			cmd.Env = os.Environ()
+			for _, key := range slices.Sorted(maps.Keys(vars)) {
+				cmd.Env = append(cmd.Env, key+"="+vars[key])
+			}
+		}
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("hook %s (%s): %w", name, hook.Run, err)
+		}
+	}
+
+	return nil
+}
+
+func hookVars(cfg config.Config, name string, tags map[string]string, failure error) map[string]string {
+	images := make([]string, 0, len(tags))
+	containers := make([]string, 0, len(cfg.Deploy.Containers))
+	vars := map[string]string{
+		"AIRO_HOOK":        name,
+		"AIRO_DEPLOY_TYPE": cfg.Deploy.Type,
+		"AIRO_ENVIRONMENT": cfg.Environment,
+		"AIRO_SSH_HOST":    cfg.Deploy.SSH.Host,
+	}
+	for _, imageName := range cfg.ImageNames() {
+		imageTag := tags[imageName]
+		images = append(images, imageTag)
+		vars["AIRO_TAG"] = tagSuffix(imageTag)
+		vars["AIRO_IMAGE_"+envName(imageName)] = imageTag
+	}
+	for _, container := range cfg.Deploy.Containers {
+		containers = append(containers, container.Name)
+	}
+	vars["AIRO_IMAGES"] = strings.Join(images, " ")
+	vars["AIRO_CONTAINERS"] = strings.Join(containers, " ")
+	if failure != nil {
+		vars["AIRO_ERROR"] = failure.Error()
+	}
+	return vars
+}
+
+func hookExports(vars map[string]string) string {
+	var b strings.Builder
+	for _, key := range slices.Sorted(maps.Keys(vars)) {
+		fmt.Fprintf(&b, "export %s=%s; ", key, shellQuote(vars[key]))
+	}
+	return b.String()
+}
+
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
@@ -0,0 +1,107 @@
+package docker
+
+import (
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"bypirob/airo/src/internal/config"
+)
+
+const imageListFormat = "{{.Repository}}\t{{.Tag}}\t{{.CreatedAt}}\t{{.Size}}"
+
+type Image struct {
+	Name    string
+	Tag     string
+	Created time.Time
+	Size    string
+	Digest  string
+}
+
+func (i Image) Ref() string {
+	return fmt.Sprintf("%s:%s", i.Name, i.Tag)
+}
+
+func listLocalImages(cfg config.Config) ([]Image, error) {
+	output, err := exec.Command("docker", "images", "--format", imageListFormat).Output()
+	if err != nil {
+		return nil, fmt.Errorf("docker images: %w", err)
+	}
+	return parseImageList(cfg, string(output)), nil
+}
+
+func listHostImages(cfg config.Config) ([]Image, error) {
+	output, err := sshCommand(cfg, "docker", "images", "--format", shellQuote(imageListFormat)).Output()
+	if err != nil {
+		return nil, fmt.Errorf("ssh docker images: %w", err)
+	}
+	return parseImageList(cfg, string(output)), nil
+}
+
+func parseImageList(cfg config.Config, output string) []Image {
+	images := make([]Image, 0)
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 4 {
+			continue
+		}
+		if _, ok := cfg.Images[fields[0]]; !ok || fields[1] == "" || fields[1] == "<none>" {
+			continue
+		}
+		created, _ := time.Parse("2006-01-02 15:04:05 -0700 MST", fields[2])
+		images = append(images, Image{Name: fields[0], Tag: fields[1], Created: created, Size: fields[3]})
+	}
+	sortImages(images)
+	return images
+}
+
+func sortImages(images []Image) {
+	sort.SliceStable(images, func(i, j int) bool {
+		if images[i].Name != images[j].Name {
+			return images[i].Name < images[j].Name
+		}
+		if !images[i].Created.Equal(images[j].Created) {
+			return images[i].Created.After(images[j].Created)
+		}
+		return images[i].Tag > images[j].Tag
+	})
+}
+
+func deployedImages(cfg config.Config) (map[string]string, error) {
+	names := make([]string, 0, len(cfg.Deploy.Containers))
+	for _, container := range cfg.Deploy.Containers {
+		names = append(names, container.Name)
+	}
+	if len(names) == 0 {
+		return map[string]string{}, nil
+	}
+
+	script := fmt.Sprintf("docker inspect --format '{{.Name}} {{.Config.Image}}' %s 2>/dev/null || true", shellJoin(names))
+	output, err := sshShell(cfg, script).Output()
+	if err != nil {
+		return nil, fmt.Errorf("ssh inspect containers: %w", err)
+	}
+
+	deployed := make(map[string]string, len(names))
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		name, image, ok := strings.Cut(line, " ")
+		if !ok {
+			continue
+		}
+		deployed[strings.TrimPrefix(name, "/")] = image
+	}
+	return deployed, nil
+}
+
+func tagTime(suffix string) time.Time {
+	if len(suffix) < len("20060102-1504") {
+		return time.Time{}
+	}
+	parsed, err := time.Parse("20060102-1504", suffix[:len("20060102-1504")])
+	if err != nil {
+		return time.Time{}
+	}
+	return parsed
+}
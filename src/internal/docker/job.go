@@ -0,0 +1,48 @@
+package docker
+
+import (
+	"fmt"
+	"os"
+
+	"bypirob/airo/src/internal/config"
+)
+
+func runJobs(cfg config.Config, tags map[string]string, phase string) error {
+	for _, job := range cfg.Jobs {
+		if job.Phase != phase {
+			continue
+		}
+		if err := runJob(cfg, job, tags[job.Image]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func runJob(cfg config.Config, job config.JobConfig, imageTag string) error {
+	name := "airo-job-" + job.Name
+	runArgs := []string{"--rm", "--name", name}
+	if job.EnvFile != "" {
+		runArgs = append(runArgs, "--env-file", job.EnvFile)
+	}
+	networks := []string{}
+	for _, network := range job.Networks {
+		if network == "" {
+			continue
+		}
+		networks = append(networks, network)
+	}
+	for _, volume := range job.Volumes {
+		runArgs = append(runArgs, "-v", volume)
+	}
+
+	fmt.Fprintf(os.Stdout, "Running job %s (%s)\n", job.Name, imageTag)
+	sshCmd := sshShell(cfg, dockerRunScript(runArgs, networks, name, append([]string{imageTag}, job.Command...), true))
+	sshCmd.Stdout = os.Stdout
+	sshCmd.Stderr = os.Stderr
+
+	if err := sshCmd.Run(); err != nil {
+		return fmt.Errorf("job %s: %w", job.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package docker
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/url"
+	"strings"
+	"time"
+
+	"bypirob/airo/src/internal/config"
+)
+
+const (
+	LabelRevision   = "org.opencontainers.image.revision"
+	LabelSource     = "org.opencontainers.image.source"
+	LabelCreated    = "org.opencontainers.image.created"
+	LabelVersion    = "org.opencontainers.image.version"
+	LabelConfigHash = "airo.config.hash"
+	LabelBuilder    = "airo.builder"
+)
+
+func buildLabels(cfg config.Config, projectPath, name, imageTag string) map[string]string {
+	labels := map[string]string{
+		LabelCreated:    time.Now().UTC().Format(time.RFC3339),
+		LabelVersion:    tagSuffix(imageTag),
+		LabelConfigHash: configHash(cfg),
+		LabelBuilder:    lockHolder(),
+	}
+	if state, err := ReadGitState(projectPath); err == nil {
+		maps.Copy(labels, state.Labels())
+		labels[LabelRevision] = state.SHA
+		if full, err := gitOutput(projectPath, "rev-parse", "HEAD"); err == nil {
+			labels[LabelRevision] = full
+		}
+	}
+	if source := gitSource(projectPath); source != "" {
+		labels[LabelSource] = source
+	}
+	maps.Copy(labels, cfg.Images[name].Labels)
+	return labels
+}
+
+func configHash(cfg config.Config) string {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+func gitSource(projectPath string) string {
+	remote, err := gitOutput(projectPath, "remote", "get-url", "origin")
+	if err != nil || remote == "" {
+		return ""
+	}
+	if parsed, err := url.Parse(remote); err == nil && parsed.Scheme != "" {
+		parsed.User = nil
+		return strings.TrimSuffix(parsed.String(), ".git")
+	}
+	if host, path, ok := strings.Cut(strings.TrimPrefix(remote, "git@"), ":"); ok && strings.HasPrefix(remote, "git@") {
+		return fmt.Sprintf("https://%s/%s", host, strings.TrimSuffix(path, ".git"))
+	}
+	return remote
+}
@@ -0,0 +1,206 @@
+package docker
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"time"
+
+	"bypirob/airo/src/internal/config"
+)
+
+const (
+	remoteStateDir    = ".airo"
+	remoteLockDir     = remoteStateDir + "/deploy.lock"
+	remoteTakeoverDir = remoteStateDir + "/deploy.lock.takeover"
+)
+
+type LockInfo struct {
+	Holder string
+	Tag    string
+	Time   time.Time
+}
+
+func (l LockInfo) String() string {
+	return fmt.Sprintf("%s (tag %s) since %s", l.Holder, l.Tag, l.Time.Local().Format(time.RFC3339))
+}
+
+type LockHeldError struct {
+	Info LockInfo
+}
+
+func (e *LockHeldError) Error() string {
+	return fmt.Sprintf("deploy lock is held by %s; run airo unlock --force if it is no longer in use", e.Info)
+}
+
+func AcquireLock(cfg config.Config, tag string) (func() error, error) {
+	timeout, err := time.ParseDuration(cfg.Deploy.LockTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("parse deploy.lock_timeout: %w", err)
+	}
+
+	info := LockInfo{Holder: lockHolder(), Tag: tag, Time: time.Now().UTC()}
+	contents := lockContents(info)
+	acquired, current, currentContents, err := tryLock(cfg, contents)
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		if !current.Time.IsZero() && time.Since(current.Time) < timeout {
+			return nil, &LockHeldError{Info: current}
+		}
+		fmt.Fprintf(os.Stderr, "Taking over stale deploy lock held by %s\n", current)
+		acquired, current, err = takeOverLock(cfg, currentContents, contents)
+		if err != nil {
+			return nil, err
+		}
+		if !acquired {
+			return nil, &LockHeldError{Info: current}
+		}
+	}
+
+	return func() error { return releaseLock(cfg, contents) }, nil
+}
+
+func Unlock(cfg config.Config, force bool) (LockInfo, error) {
+	current, held, err := readLock(cfg)
+	if err != nil {
+		return LockInfo{}, err
+	}
+	if !held {
+		return LockInfo{}, fmt.Errorf("deploy lock is not held")
+	}
+	if !force && current.Holder != lockHolder() {
+		return current, &LockHeldError{Info: current}
+	}
+	return current, removeLock(cfg)
+}
+
+func lockContents(info LockInfo) string {
+	nonce := make([]byte, 8)
+	rand.Read(nonce)
+	return fmt.Sprintf("holder=%s\ntag=%s\ntime=%s\nid=%s\n", info.Holder, info.Tag, info.Time.Format(time.RFC3339), hex.EncodeToString(nonce))
+}
+
+func tryLock(cfg config.Config, contents string) (bool, LockInfo, string, error) {
+	script := fmt.Sprintf(
+		"mkdir -p %[1]s && if mkdir %[2]s 2>/dev/null; then printf %%s %[3]s > %[2]s/info; echo acquired; "+
+			"else echo \"$(stat -c %%Y %[2]s 2>/dev/null || date -r %[2]s +%%s 2>/dev/null)\"; cat %[2]s/info 2>/dev/null || true; fi",
+		remoteStateDir, remoteLockDir, shellQuote(contents),
+	)
+
+	output, err := sshShell(cfg, script).Output()
+	if err != nil {
+		return false, LockInfo{}, "", fmt.Errorf("ssh lock: %w", err)
+	}
+	if strings.TrimSpace(string(output)) == "acquired" {
+		return true, parseLockInfo(contents), contents, nil
+	}
+
+	mtime, current, _ := strings.Cut(string(output), "\n")
+	info := parseLockInfo(current)
+	if info.Time.IsZero() {
+		if seconds, err := strconv.ParseInt(strings.TrimSpace(mtime), 10, 64); err == nil {
+			info.Time = time.Unix(seconds, 0).UTC()
+		}
+	}
+	return false, info, current, nil
+}
+
+func takeOverLock(cfg config.Config, stale, contents string) (bool, LockInfo, error) {
+	script := fmt.Sprintf(
+		"mkdir %[1]s 2>/dev/null || { echo busy; exit 0; }; trap 'rmdir %[1]s' EXIT; "+
+			"current=$(cat %[2]s/info 2>/dev/null); "+
+			"if [ \"$current\" = %[3]s ] || [ ! -d %[2]s ]; then rm -rf %[2]s && mkdir %[2]s && printf %%s %[4]s > %[2]s/info && echo acquired; "+
+			"else printf %%s \"$current\"; fi",
+		remoteTakeoverDir, remoteLockDir, shellQuote(strings.TrimRight(stale, "\n")), shellQuote(contents),
+	)
+
+	output, err := sshShell(cfg, script).Output()
+	if err != nil {
+		return false, LockInfo{}, fmt.Errorf("ssh take over lock: %w", err)
+	}
+	switch strings.TrimSpace(string(output)) {
+	case "acquired":
+		return true, parseLockInfo(contents), nil
+	case "busy":
+		return false, LockInfo{}, fmt.Errorf("another client is taking over the deploy lock; if none is, remove ~/%s on the server", remoteTakeoverDir)
+	default:
+		return false, parseLockInfo(string(output)), nil
+	}
+}
+
+func releaseLock(cfg config.Config, contents string) error {
+	script := fmt.Sprintf(
+		"if [ \"$(cat %[1]s/info 2>/dev/null)\" = %[2]s ]; then rm -rf %[1]s; else echo lost; fi",
+		remoteLockDir, shellQuote(strings.TrimRight(contents, "\n")),
+	)
+	output, err := sshShell(cfg, script).Output()
+	if err != nil {
+		return fmt.Errorf("ssh unlock: %w", err)
+	}
+	if strings.TrimSpace(string(output)) == "lost" {
+		fmt.Fprintln(os.Stderr, "Warning: the deploy lock was taken over by someone else before this run finished; left it in place")
+	}
+	return nil
+}
+
+func readLock(cfg config.Config) (LockInfo, bool, error) {
+	script := fmt.Sprintf("if [ -d %s ]; then echo held; cat %s/info 2>/dev/null || true; fi", remoteLockDir, remoteLockDir)
+	output, err := sshShell(cfg, script).Output()
+	if err != nil {
+		return LockInfo{}, false, fmt.Errorf("ssh read lock: %w", err)
+	}
+
+	text := string(output)
+	if !strings.HasPrefix(text, "held") {
+		return LockInfo{}, false, nil
+	}
+	return parseLockInfo(strings.TrimPrefix(text, "held")), true, nil
+}
+
+func removeLock(cfg config.Config) error {
+	if err := sshShell(cfg, fmt.Sprintf("rm -rf %s", remoteLockDir)).Run(); err != nil {
+		return fmt.Errorf("ssh unlock: %w", err)
+	}
+	return nil
+}
+
+func parseLockInfo(text string) LockInfo {
+	info := LockInfo{Holder: "unknown", Tag: "unknown"}
+	scanner := bufio.NewScanner(strings.NewReader(text))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(scanner.Text()), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "holder":
+			info.Holder = value
+		case "tag":
+			info.Tag = value
+		case "time":
+			if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+				info.Time = parsed
+			}
+		}
+	}
+	return info
+}
+
+func lockHolder() string {
+	name := "unknown"
+	if current, err := user.Current(); err == nil && current.Username != "" {
+		name = current.Username
+	}
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		return name
+	}
+	return fmt.Sprintf("%s@%s", name, host)
+}
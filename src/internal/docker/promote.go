@@ -0,0 +1,73 @@
+package docker
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"bypirob/airo/src/internal/config"
+)
+
+func PromoteImage(from, to config.Config, tag string) error {
+	tags, err := resolveTags(to, "", tag)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case from.Deploy.Type == "ssh" && to.Deploy.Type == "ssh":
+		for _, name := range to.ImageNames() {
+			if err := copyOverSSH(from, to, tags[name]); err != nil {
+				return fmt.Errorf("copy %s: %w", name, err)
+			}
+		}
+	case from.Deploy.Type == "registry" && to.Deploy.Type == "registry":
+		for _, name := range to.ImageNames() {
+			suffix := tagSuffix(tags[name])
+			cmd := exec.Command("docker", "buildx", "imagetools", "create",
+				"--tag", registryRef(to, name, suffix), registryRef(from, name, suffix))
+			cmd.Stdout = os.Stdout
+			cmd.Stderr = os.Stderr
+			if err := cmd.Run(); err != nil {
+				return fmt.Errorf("docker buildx imagetools create (%s): %w", name, err)
+			}
+		}
+	default:
+		return fmt.Errorf("promote from %s to %s deploys is not supported", from.Deploy.Type, to.Deploy.Type)
+	}
+
+	return nil
+}
+
+func copyOverSSH(from, to config.Config, imageTag string) error {
+	saveCmd := sshCommand(from, "docker", "save", shellQuote(imageTag))
+	loadCmd := sshCommand(to, "docker", "load")
+
+	pipe, err := saveCmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("prepare docker save output: %w", err)
+	}
+
+	saveCmd.Stderr = os.Stderr
+	loadCmd.Stdin = pipe
+	loadCmd.Stdout = os.Stdout
+	loadCmd.Stderr = os.Stderr
+
+	if err := loadCmd.Start(); err != nil {
+		return fmt.Errorf("start ssh docker load: %w", err)
+	}
+	if err := saveCmd.Start(); err != nil {
+		return fmt.Errorf("start ssh docker save: %w", err)
+	}
+
+	saveErr := saveCmd.Wait()
+	loadErr := loadCmd.Wait()
+
+	if saveErr != nil {
+		return fmt.Errorf("ssh docker save: %w", saveErr)
+	}
+	if loadErr != nil {
+		return fmt.Errorf("ssh docker load: %w", loadErr)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package docker
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"bypirob/airo/src/internal/config"
+)
+
+const remoteProxyDir = remoteStateDir + "/proxy"
+
+func usesProxy(containers []config.ContainerConfig) bool {
+	for _, container := range containers {
+		if len(container.Domains) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func ensureProxyNetwork(cfg config.Config) error {
+	network := cfg.Deploy.Proxy.Network
+	script := fmt.Sprintf("docker network inspect %s >/dev/null 2>&1 || docker network create --label %s %s >/dev/null",
+		shellQuote(network), managedLabel, shellQuote(network))
+	if err := sshShell(cfg, script).Run(); err != nil {
+		return fmt.Errorf("ssh create proxy network %s: %w", network, err)
+	}
+	return nil
+}
+
+func updateProxy(cfg config.Config, containers []config.ContainerConfig) error {
+	var script strings.Builder
+	if !usesProxy(containers) {
+		fmt.Fprintf(&script, "[ -d %s ] || exit 0; ", remoteProxyDir)
+	}
+	fmt.Fprintf(&script, "set -e; mkdir -p %s/routes; ", remoteProxyDir)
+	fmt.Fprintf(&script, "printf %%s %s > %s/Caddyfile; ", shellQuote(proxyCaddyfile(cfg)), remoteProxyDir)
+	for _, container := range containers {
+		routeFile := fmt.Sprintf("%s/routes/%s.caddy", remoteProxyDir, container.Name)
+		if len(container.Domains) == 0 {
+			fmt.Fprintf(&script, "rm -f %s; ", shellQuote(routeFile))
+			continue
+		}
+		fmt.Fprintf(&script, "printf %%s %s > %s; ", shellQuote(proxyRoute(container)), shellQuote(routeFile))
+	}
+
+	name := shellQuote(cfg.Deploy.Proxy.Name)
+	runArgs := shellJoin([]string{
+		"docker", "run", "-d", "--name", cfg.Deploy.Proxy.Name,
+		"--restart", "unless-stopped",
+		"--network", cfg.Deploy.Proxy.Network,
+		"-p", "80:80", "-p", "443:443", "-p", "443:443/udp",
+		"-v", "airo-proxy-data:/data",
+		"-v", "airo-proxy-config:/config",
+	})
+	fmt.Fprintf(&script,
+		"if [ \"$(docker inspect --format '{{.State.Running}}' %s 2>/dev/null)\" = true ]; then "+
+			"docker exec %s caddy reload --config /etc/caddy/Caddyfile --adapter caddyfile; ",
+		name, name)
+	if usesProxy(containers) {
+		fmt.Fprintf(&script, "else docker rm -f %s >/dev/null 2>&1 || true; %s -v \"$HOME/%s:/etc/caddy\" %s; ",
+			name, runArgs, remoteProxyDir, shellQuote(cfg.Deploy.Proxy.Image))
+	}
+	script.WriteString("fi")
+
+	sshCmd := sshShell(cfg, script.String())
+	sshCmd.Stdout = os.Stdout
+	sshCmd.Stderr = os.Stderr
+	if err := sshCmd.Run(); err != nil {
+		return fmt.Errorf("ssh update proxy: %w", err)
+	}
+	return nil
+}
+
+func proxyCaddyfile(cfg config.Config) string {
+	var b strings.Builder
+	if cfg.Deploy.Proxy.Email != "" {
+		fmt.Fprintf(&b, "{\n\temail %s\n}\n\n", cfg.Deploy.Proxy.Email)
+	}
+	b.WriteString("import routes/*.caddy\n")
+	return b.String()
+}
+
+func proxyRoute(container config.ContainerConfig) string {
+	return fmt.Sprintf("%s {\n\treverse_proxy %s:%d\n}\n", strings.Join(container.Domains, ", "), container.Name, container.AppPort)
+}
@@ -0,0 +1,178 @@
+package docker
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"bypirob/airo/src/internal/config"
+)
+
+type PruneOptions struct {
+	Resources     bool
+	Volumes       bool
+	Local         bool
+	Host          bool
+	Registry      bool
+	KeepLast      int
+	KeepNewerThan time.Duration
+}
+
+type PruneCandidate struct {
+	Kind   string
+	Name   string
+	remove func() error
+}
+
+func (c PruneCandidate) Remove() error {
+	return c.remove()
+}
+
+func PlanPrune(cfg config.Config, opts PruneOptions) ([]PruneCandidate, error) {
+	candidates := make([]PruneCandidate, 0)
+	kinds := make([]string, 0, 2)
+	if opts.Resources {
+		kinds = append(kinds, "network")
+	}
+	if opts.Volumes {
+		kinds = append(kinds, "volume")
+	}
+	if len(kinds) > 0 {
+		resources, err := planResourcePrune(cfg, kinds)
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, resources...)
+	}
+	if !opts.Local && !opts.Host && !opts.Registry {
+		return candidates, nil
+	}
+
+	protected := map[string]struct{}{}
+	if cfg.Deploy.SSH.Host != "" {
+		deployed, err := deployedImages(cfg)
+		if err != nil {
+			return nil, err
+		}
+		for _, ref := range deployed {
+			protected[ref] = struct{}{}
+		}
+	}
+
+	if opts.Local {
+		images, err := listLocalImages(cfg)
+		if err != nil {
+			return nil, err
+		}
+		for _, image := range expiredImages(images, opts, protected) {
+			candidates = append(candidates, PruneCandidate{
+				Kind: "local image",
+				Name: image.Ref(),
+				remove: func() error {
+					if output, err := exec.Command("docker", "rmi", image.Ref()).CombinedOutput(); err != nil {
+						return fmt.Errorf("%w (%s)", err, strings.TrimSpace(string(output)))
+					}
+					return nil
+				},
+			})
+		}
+	}
+	if opts.Host {
+		images, err := listHostImages(cfg)
+		if err != nil {
+			return nil, err
+		}
+		for _, image := range expiredImages(images, opts, protected) {
+			candidates = append(candidates, PruneCandidate{
+				Kind: "host image",
+				Name: image.Ref(),
+				remove: func() error {
+					return runSSHQuiet(cfg, "docker", "rmi", shellQuote(image.Ref()))
+				},
+			})
+		}
+	}
+	if opts.Registry {
+		images, skipped, err := registryImages(cfg)
+		if err != nil {
+			return nil, err
+		}
+		for _, tag := range skipped {
+			if !tag.Missing() {
+				return nil, fmt.Errorf("cannot inspect registry tag %s, refusing to prune the registry: %w", tag.Tag, tag.Err)
+			}
+		}
+		for _, image := range images {
+			if image.Created.IsZero() {
+				return nil, fmt.Errorf("cannot tell when registry tag %s-%s was created, refusing to prune the registry", image.Name, image.Tag)
+			}
+		}
+		candidates = append(candidates, registryPruneCandidates(cfg, images, expiredImages(images, opts, protected))...)
+	}
+
+	return candidates, nil
+}
+
+func registryPruneCandidates(cfg config.Config, images, expired []Image) []PruneCandidate {
+	expiredRefs := make(map[string]struct{}, len(expired))
+	for _, image := range expired {
+		expiredRefs[image.Ref()] = struct{}{}
+	}
+	keptDigests := make(map[string]struct{})
+	for _, image := range images {
+		if _, ok := expiredRefs[image.Ref()]; !ok {
+			keptDigests[image.Digest] = struct{}{}
+		}
+	}
+
+	candidates := make([]PruneCandidate, 0, len(expired))
+	byDigest := make(map[string]int)
+	for _, image := range expired {
+		name := fmt.Sprintf("%s:%s-%s", cfg.Deploy.Registry.Repository, image.Name, image.Tag)
+		if _, ok := keptDigests[image.Digest]; ok {
+			continue
+		}
+		if i, ok := byDigest[image.Digest]; ok {
+			candidates[i].Name += ", " + name
+			continue
+		}
+		byDigest[image.Digest] = len(candidates)
+		candidates = append(candidates, PruneCandidate{
+			Kind: "registry tag",
+			Name: name,
+			remove: func() error {
+				return deleteRegistryImage(cfg, image)
+			},
+		})
+	}
+	return candidates
+}
+
+func expiredImages(images []Image, opts PruneOptions, protected map[string]struct{}) []Image {
+	keep := make(map[string]struct{}, len(images))
+	counts := make(map[string]int)
+	for i, image := range images {
+		if _, ok := protected[image.Ref()]; ok {
+			keep[image.Ref()] = struct{}{}
+			if i+1 < len(images) && images[i+1].Name == image.Name {
+				keep[images[i+1].Ref()] = struct{}{}
+			}
+		}
+		if counts[image.Name] < opts.KeepLast {
+			keep[image.Ref()] = struct{}{}
+		}
+		if opts.KeepNewerThan > 0 && !image.Created.IsZero() && time.Since(image.Created) < opts.KeepNewerThan {
+			keep[image.Ref()] = struct{}{}
+		}
+		counts[image.Name]++
+	}
+
+	expired := make([]Image, 0)
+	for _, image := range images {
+		if _, ok := keep[image.Ref()]; !ok {
+			expired = append(expired, image)
+		}
+	}
+	return expired
+}
@@ -21,6 +21,10 @@ func PushImage(cfg config.Config, projectPath, tag string) error {
 
 	switch cfg.Deploy.Type {
 	case "ssh":
+		if cfg.Build.Mode == config.BuildModeRemote {
+			fmt.Printf("Skipping push, images were built on %s\n", cfg.Deploy.SSH.Host)
+			return nil
+		}
 		return pushOverSSH(cfg, tags)
 	case "registry":
 		return pushToRegistry(cfg, tags)
@@ -30,7 +34,8 @@ func PushImage(cfg config.Config, projectPath, tag string) error {
 }
 
 func pushOverSSH(cfg config.Config, tags map[string]string) error {
-	for name, tag := range tags {
+	for _, name := range cfg.ImageNames() {
+		tag := tags[name]
 		saveCmd := exec.Command("docker", "save", tag)
 		sshCmd := sshCommand(cfg, "docker", "load")
 
@@ -66,12 +71,13 @@ func pushOverSSH(cfg config.Config, tags map[string]string) error {
 }
 
 func pushToRegistry(cfg config.Config, tags map[string]string) error {
-	for name, tag := range tags {
-		tagSuffix := tagSuffix(tag)
-		target := fmt.Sprintf("%s:%s-%s", cfg.Deploy.Registry.Repository, name, tagSuffix)
-		if cfg.Deploy.Registry.RegistryURL != "" {
-			target = fmt.Sprintf("%s/%s", strings.TrimSuffix(cfg.Deploy.Registry.RegistryURL, "/"), target)
+	for _, name := range cfg.ImageNames() {
+		tag := tags[name]
+		if pushedOnBuild(cfg, name) {
+			fmt.Fprintf(os.Stdout, "Skipping %s, multi-platform images are pushed by build\n", name)
+			continue
 		}
+		target := registryRef(cfg, name, tagSuffix(tag))
 
 		tagCmd := exec.Command("docker", "tag", tag, target)
 		tagCmd.Stdout = os.Stdout
@@ -90,3 +96,11 @@ func pushToRegistry(cfg config.Config, tags map[string]string) error {
 
 	return nil
 }
+
+func registryRef(cfg config.Config, name, suffix string) string {
+	ref := fmt.Sprintf("%s:%s-%s", cfg.Deploy.Registry.Repository, name, suffix)
+	if cfg.Deploy.Registry.RegistryURL != "" {
+		ref = fmt.Sprintf("%s/%s", strings.TrimSuffix(cfg.Deploy.Registry.RegistryURL, "/"), ref)
+	}
+	return ref
+}
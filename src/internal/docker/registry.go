@@ -0,0 +1,239 @@
+package docker
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"bypirob/airo/src/internal/config"
+)
+
+const registryConcurrency = 8
+
+type registryClient struct {
+	http       *http.Client
+	base       string
+	host       string
+	repository string
+
+	mu            sync.Mutex
+	authorization string
+}
+
+type registryError struct {
+	Request    string
+	StatusCode int
+	Status     string
+}
+
+func (e *registryError) Error() string {
+	return fmt.Sprintf("registry %s request failed: %s", e.Request, e.Status)
+}
+
+func newRegistryClient(cfg config.Config) (*registryClient, error) {
+	base, err := registryBase(cfg)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := url.Parse(base)
+	if err != nil {
+		return nil, fmt.Errorf("parse registry url (%s): %w", base, err)
+	}
+	return &registryClient{
+		http:       &http.Client{Timeout: 10 * time.Second},
+		base:       base,
+		host:       parsed.Host,
+		repository: cfg.Deploy.Registry.Repository,
+	}, nil
+}
+
+func (c *registryClient) do(method, path, accept string) (*http.Response, error) {
+	resp, err := c.send(method, path, accept)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	challenge := resp.Header.Get("WWW-Authenticate")
+	resp.Body.Close()
+	if err := c.authenticate(challenge); err != nil {
+		return nil, err
+	}
+	return c.send(method, path, accept)
+}
+
+func (c *registryClient) send(method, path, accept string) (*http.Response, error) {
+	req, err := http.NewRequest(method, c.base+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	c.mu.Lock()
+	authorization := c.authorization
+	c.mu.Unlock()
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	return c.http.Do(req)
+}
+
+func (c *registryClient) authenticate(challenge string) error {
+	scheme, params := parseChallenge(challenge)
+	username, password := registryCredentials(c.host)
+
+	switch strings.ToLower(scheme) {
+	case "basic":
+		if username == "" {
+			return fmt.Errorf("registry %s requires credentials, run docker login %s", c.host, c.host)
+		}
+		c.setAuthorization("Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password)))
+		return nil
+	case "bearer":
+	default:
+		return fmt.Errorf("registry %s returned 401 without a supported authentication challenge", c.host)
+	}
+
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return fmt.Errorf("registry %s returned an invalid token realm %q", c.host, params["realm"])
+	}
+	query := realm.Query()
+	if service := params["service"]; service != "" {
+		query.Set("service", service)
+	}
+	for _, scope := range strings.Fields(params["scope"]) {
+		query.Add("scope", scope)
+	}
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return err
+	}
+	if username != "" {
+		req.SetBasicAuth(username, password)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("fetch registry token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &registryError{Request: "token", StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+	var token struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return fmt.Errorf("parse registry token: %w", err)
+	}
+	if token.Token == "" {
+		token.Token = token.AccessToken
+	}
+	if token.Token == "" {
+		return fmt.Errorf("registry %s returned an empty token", c.host)
+	}
+	c.setAuthorization("Bearer " + token.Token)
+	return nil
+}
+
+func (c *registryClient) setAuthorization(value string) {
+	c.mu.Lock()
+	c.authorization = value
+	c.mu.Unlock()
+}
+
+func parseChallenge(challenge string) (string, map[string]string) {
+	scheme, rest, _ := strings.Cut(strings.TrimSpace(challenge), " ")
+	params := map[string]string{}
+	for rest != "" {
+		key, value, ok := strings.Cut(strings.TrimLeft(rest, " ,"), "=")
+		if !ok {
+			break
+		}
+		if strings.HasPrefix(value, "\"") {
+			end := strings.Index(value[1:], "\"")
+			if end < 0 {
+				params[strings.ToLower(key)] = value[1:]
+				break
+			}
+			params[strings.ToLower(key)] = value[1 : end+1]
+			rest = value[end+2:]
+			continue
+		}
+		value, rest, _ = strings.Cut(value, ",")
+		params[strings.ToLower(key)] = strings.TrimSpace(value)
+	}
+	return scheme, params
+}
+
+func registryCredentials(host string) (string, string) {
+	dir := os.Getenv("DOCKER_CONFIG")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", ""
+		}
+		dir = filepath.Join(home, ".docker")
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		return "", ""
+	}
+	var dockerConfig struct {
+		Auths map[string]struct {
+			Auth string `json:"auth"`
+		} `json:"auths"`
+		CredsStore  string            `json:"credsStore"`
+		CredHelpers map[string]string `json:"credHelpers"`
+	}
+	if err := json.Unmarshal(data, &dockerConfig); err != nil {
+		return "", ""
+	}
+
+	for _, key := range []string{host, "https://" + host, "http://" + host} {
+		entry, ok := dockerConfig.Auths[key]
+		if !ok || entry.Auth == "" {
+			continue
+		}
+		decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+		if err != nil {
+			continue
+		}
+		if username, password, ok := strings.Cut(string(decoded), ":"); ok {
+			return username, password
+		}
+	}
+
+	helper := dockerConfig.CredHelpers[host]
+	if helper == "" {
+		helper = dockerConfig.CredsStore
+	}
+	if helper == "" {
+		return "", ""
+	}
+	cmd := exec.Command("docker-credential-"+helper, "get")
+	cmd.Stdin = bytes.NewBufferString(host)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", ""
+	}
+	var credentials struct {
+		Username string `json:"Username"`
+		Secret   string `json:"Secret"`
+	}
+	if err := json.Unmarshal(output, &credentials); err != nil {
+		return "", ""
+	}
+	return credentials.Username, credentials.Secret
+}
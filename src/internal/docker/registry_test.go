@@ -0,0 +1,87 @@
+package docker
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"bypirob/airo/src/internal/config"
+)
+
+func TestParseChallenge(t *testing.T) {
+	scheme, params := parseChallenge(`Bearer realm="https://auth.example.com/token",service="registry.example.com",scope="repository:app:pull,push"`)
+	if scheme != "Bearer" {
+		t.Errorf("scheme = %q, want Bearer", scheme)
+	}
+	want := map[string]string{
+		"realm":   "https://auth.example.com/token",
+		"service": "registry.example.com",
+		"scope":   "repository:app:pull,push",
+	}
+	for key, value := range want {
+		if params[key] != value {
+			t.Errorf("params[%q] = %q, want %q", key, params[key], value)
+		}
+	}
+}
+
+func TestRegistryImages(t *testing.T) {
+	dockerConfig := t.TempDir()
+	auth := base64.StdEncoding.EncodeToString([]byte("user:secret"))
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/token" {
+			if username, password, ok := r.BasicAuth(); !ok || username != "user" || password != "secret" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, `{"token":"abc"}`)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer abc" {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="test",scope="repository:repo:pull"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/repo/tags/list":
+			fmt.Fprint(w, `{"name":"repo","tags":["app-1","app-2","other-1"]}`)
+		case "/v2/repo/manifests/app-1":
+			w.Header().Set("Docker-Content-Digest", "sha256:one")
+			fmt.Fprint(w, `{"config":{"digest":"sha256:config"}}`)
+		case "/v2/repo/blobs/sha256:config":
+			fmt.Fprint(w, `{"created":"2026-01-02T03:04:05Z"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	data := fmt.Sprintf(`{"auths":{%q:{"auth":%q}}}`, host, auth)
+	if err := os.WriteFile(filepath.Join(dockerConfig, "config.json"), []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("DOCKER_CONFIG", dockerConfig)
+
+	cfg := config.Config{
+		Images: map[string]config.ImageConfig{"app": {}},
+		Deploy: config.DeployConfig{Registry: config.RegistryConfig{RegistryURL: server.URL, Repository: "repo"}},
+	}
+	images, skipped, err := registryImages(cfg)
+	if err != nil {
+		t.Fatalf("registryImages() error = %v", err)
+	}
+	if len(images) != 1 || images[0].Tag != "1" || images[0].Digest != "sha256:one" || !images[0].Created.Equal(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("registryImages() images = %+v, want app:1 with its digest and creation time", images)
+	}
+	if len(skipped) != 1 || skipped[0].Tag != "app-2" || !skipped[0].Missing() {
+		t.Errorf("registryImages() skipped = %+v, want missing app-2", skipped)
+	}
+}
@@ -0,0 +1,53 @@
+package docker
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"bypirob/airo/src/internal/config"
+)
+
+func RemoteBuilderName(cfg config.Config) string {
+	return "airo-" + sanitizeTag(cfg.Deploy.SSH.Host)
+}
+
+func remoteBuilderEndpoint(cfg config.Config) string {
+	target := cfg.Deploy.SSH.Host
+	if cfg.Deploy.SSH.User != "" {
+		target = cfg.Deploy.SSH.User + "@" + target
+	}
+	if cfg.Deploy.SSH.Port != 0 {
+		target = fmt.Sprintf("%s:%d", target, cfg.Deploy.SSH.Port)
+	}
+	return "ssh://" + target
+}
+
+func ensureRemoteBuilder(cfg config.Config) (string, error) {
+	name := RemoteBuilderName(cfg)
+	endpoint := remoteBuilderEndpoint(cfg)
+
+	output, err := exec.Command("docker", "context", "inspect", "--format", "{{.Endpoints.docker.Host}}", name).Output()
+	if err == nil {
+		if current := strings.TrimSpace(string(output)); current != endpoint {
+			return "", fmt.Errorf("docker context %s points to %s, expected %s", name, current, endpoint)
+		}
+		return name, nil
+	}
+
+	if cfg.Deploy.SSH.IdentityFile != "" {
+		fmt.Fprintf(os.Stderr, "Warning: remote builds connect with your ssh agent or ~/.ssh/config, deploy.ssh.identity_file is not passed to docker\n")
+	}
+	fmt.Printf("Creating remote builder %s (%s)\n", name, endpoint)
+	cmd := exec.Command("docker", "context", "create", name,
+		"--description", "airo remote builder",
+		"--docker", "host="+endpoint,
+	)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("docker context create (%s): %w", name, err)
+	}
+	return name, nil
+}
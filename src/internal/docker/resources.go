@@ -0,0 +1,125 @@
+package docker
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"bypirob/airo/src/internal/config"
+)
+
+const managedLabel = "airo.managed=true"
+
+func ensureResources(cfg config.Config, containers []config.ContainerConfig) error {
+	networks, volumes := referencedResources(cfg, containers, cfg.Jobs)
+	if len(networks) == 0 && len(volumes) == 0 {
+		return nil
+	}
+
+	commands := make([]string, 0, len(networks)+len(volumes))
+	for _, name := range networks {
+		create := resourceCreateArgs([]string{"docker", "network", "create"}, cfg.Networks[name], name)
+		commands = append(commands, fmt.Sprintf("docker network inspect %s >/dev/null 2>&1 || { echo %s; %s >/dev/null; }",
+			shellQuote(name), shellQuote("Created network "+name), shellJoin(create)))
+	}
+	for _, name := range volumes {
+		create := resourceCreateArgs([]string{"docker", "volume", "create"}, cfg.Volumes[name], name)
+		commands = append(commands, fmt.Sprintf("docker volume inspect %s >/dev/null 2>&1 || { echo %s; %s >/dev/null; }",
+			shellQuote(name), shellQuote("Created volume "+name), shellJoin(create)))
+	}
+
+	sshCmd := sshShell(cfg, "set -e; "+strings.Join(commands, "; "))
+	sshCmd.Stdout = os.Stdout
+	sshCmd.Stderr = os.Stderr
+	if err := sshCmd.Run(); err != nil {
+		return fmt.Errorf("ssh create networks and volumes: %w", err)
+	}
+	return nil
+}
+
+func resourceCreateArgs(args []string, resource config.ResourceConfig, name string) []string {
+	if resource.Driver != "" {
+		args = append(args, "--driver", resource.Driver)
+	}
+	for _, key := range slices.Sorted(maps.Keys(resource.Options)) {
+		args = append(args, "--opt", fmt.Sprintf("%s=%s", key, resource.Options[key]))
+	}
+	args = append(args, "--label", managedLabel)
+	for _, key := range slices.Sorted(maps.Keys(resource.Labels)) {
+		args = append(args, "--label", fmt.Sprintf("%s=%s", key, resource.Labels[key]))
+	}
+	return append(args, name)
+}
+
+func referencedResources(cfg config.Config, containers []config.ContainerConfig, jobs []config.JobConfig) ([]string, []string) {
+	networks := make([]string, 0)
+	volumes := make([]string, 0)
+	addVolumes := func(mounts []string) {
+		for _, mount := range mounts {
+			source, _, _ := strings.Cut(mount, ":")
+			if config.IsNamedVolume(source) && !slices.Contains(volumes, source) {
+				volumes = append(volumes, source)
+			}
+		}
+	}
+	addNetworks := func(names []string) {
+		for _, name := range names {
+			if name != "" && name != cfg.Deploy.Proxy.Network && !slices.Contains(networks, name) {
+				networks = append(networks, name)
+			}
+		}
+	}
+
+	for _, container := range containers {
+		addNetworks(container.Networks)
+		addVolumes(container.Volumes)
+	}
+	for _, job := range jobs {
+		addNetworks(job.Networks)
+		addVolumes(job.Volumes)
+	}
+	return networks, volumes
+}
+
+func planResourcePrune(cfg config.Config, kinds []string) ([]PruneCandidate, error) {
+	networks, volumes := referencedResources(cfg, cfg.Deploy.Containers, cfg.Jobs)
+	if usesProxy(cfg.Deploy.Containers) {
+		networks = append(networks, cfg.Deploy.Proxy.Network)
+	}
+
+	candidates := make([]PruneCandidate, 0)
+	for _, kind := range kinds {
+		keep := networks
+		if kind == "volume" {
+			keep = volumes
+		}
+
+		output, err := sshCommand(cfg, "docker", kind, "ls", "--filter", "label="+managedLabel, "--format", "'{{.Name}}'").Output()
+		if err != nil {
+			return nil, fmt.Errorf("ssh list %ss: %w", kind, err)
+		}
+		for _, name := range strings.Fields(string(output)) {
+			if slices.Contains(keep, name) {
+				continue
+			}
+			candidates = append(candidates, PruneCandidate{
+				Kind: kind,
+				Name: name,
+				remove: func() error {
+					return runSSHQuiet(cfg, "docker", kind, "rm", shellQuote(name))
+				},
+			})
+		}
+	}
+
+	return candidates, nil
+}
+
+func runSSHQuiet(cfg config.Config, remoteArgs ...string) error {
+	if output, err := sshCommand(cfg, remoteArgs...).CombinedOutput(); err != nil {
+		return fmt.Errorf("%w (%s)", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package docker
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"bypirob/airo/src/internal/config"
+	"bypirob/airo/src/internal/sbom"
+)
+
+const (
+	localStateDir = ".airo"
+	sbomDir       = localStateDir + "/sbom"
+)
+
+func LoadSBOMs(cfg config.Config, projectPath, tag string) ([]sbom.Document, error) {
+	if projectPath == "" {
+		projectPath = "."
+	}
+
+	tags, err := resolveTags(cfg, projectPath, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make([]sbom.Document, 0, len(tags))
+	for _, name := range cfg.ImageNames() {
+		data, err := os.ReadFile(sbomPath(projectPath, name, tags[name]))
+		if errors.Is(err, os.ErrNotExist) {
+			doc, err := writeSBOM(cfg, projectPath, name, tags[name])
+			if err != nil {
+				return nil, err
+			}
+			docs = append(docs, doc)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read sbom (%s): %w", name, err)
+		}
+
+		var doc sbom.Document
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return nil, fmt.Errorf("parse sbom (%s): %w", name, err)
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
+
+func SaveScanReport(cfg config.Config, tag string, report sbom.Report) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode scan report: %w", err)
+	}
+
+	dir := remoteStateDir + "/scans"
+	cmd := sshShell(cfg, fmt.Sprintf("mkdir -p %s && cat > %s", dir, shellQuote(fmt.Sprintf("%s/%s.json", dir, tagSuffix(tag)))))
+	cmd.Stdin = bytes.NewReader(data)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("ssh save scan report: %w (%s)", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func writeSBOM(cfg config.Config, projectPath, name, imageTag string) (sbom.Document, error) {
+	var doc sbom.Document
+	var err error
+	if pushedOnBuild(cfg, name) {
+		doc, err = registrySBOM(cfg, name, imageTag)
+	} else {
+		doc, err = imageSBOM(imageTag, "")
+	}
+	if err != nil {
+		return sbom.Document{}, fmt.Errorf("sbom (%s): %w", name, err)
+	}
+
+	path := sbomPath(projectPath, name, imageTag)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return sbom.Document{}, fmt.Errorf("create sbom directory: %w", err)
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return sbom.Document{}, fmt.Errorf("encode sbom (%s): %w", name, err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return sbom.Document{}, fmt.Errorf("write sbom (%s): %w", name, err)
+	}
+	fmt.Fprintf(os.Stdout, "Wrote SBOM for %s to %s (%d packages)\n", imageTag, path, len(doc.Components))
+	if len(doc.Components) == 0 {
+		fmt.Fprintf(os.Stderr, "Warning: %s has no apk or dpkg package database; scratch and distroless images cannot be scanned\n", imageTag)
+	}
+	return doc, nil
+}
+
+func registrySBOM(cfg config.Config, name, imageTag string) (sbom.Document, error) {
+	ref := registryRef(cfg, name, tagSuffix(imageTag))
+	output, err := exec.Command("docker", "buildx", "imagetools", "inspect", "--raw", ref).Output()
+	if err != nil {
+		return sbom.Document{}, fmt.Errorf("docker buildx imagetools inspect (%s): %w", ref, err)
+	}
+	var index registryManifest
+	if err := json.Unmarshal(output, &index); err != nil {
+		return sbom.Document{}, fmt.Errorf("parse manifest (%s): %w", ref, err)
+	}
+
+	repository := ref[:strings.LastIndex(ref, ":")]
+	doc := sbom.New(imageTag)
+	seen := make(map[string]bool)
+	for _, manifest := range index.Manifests {
+		platform := manifest.Platform
+		if platform.OS == "" || platform.OS == "unknown" {
+			continue
+		}
+		spec := platform.OS + "/" + platform.Architecture
+		if platform.Variant != "" {
+			spec += "/" + platform.Variant
+		}
+		part, err := imageSBOM(repository+"@"+manifest.Digest, spec)
+		if err != nil {
+			return sbom.Document{}, fmt.Errorf("%s: %w", spec, err)
+		}
+		for _, component := range part.Components {
+			if !seen[component.PURL] {
+				seen[component.PURL] = true
+				doc.Components = append(doc.Components, component)
+			}
+		}
+	}
+	return doc, nil
+}
+
+func imageSBOM(imageRef, platform string) (sbom.Document, error) {
+	args := []string{"create"}
+	if platform != "" {
+		args = append(args, "--platform", platform)
+	}
+	output, err := exec.Command("docker", append(args, imageRef)...).Output()
+	if err != nil {
+		return sbom.Document{}, fmt.Errorf("docker create: %w", err)
+	}
+	containerID := strings.TrimSpace(string(output))
+	defer exec.Command("docker", "rm", "-f", containerID).Run()
+
+	doc := sbom.New(imageRef)
+	for _, ecosystem := range []string{sbom.EcosystemAPK, sbom.EcosystemDeb} {
+		data, err := exec.Command("docker", "cp", containerID+":"+sbom.PackageDatabases[ecosystem], "-").Output()
+		if err != nil {
+			continue
+		}
+		database, err := readSingleFile(data)
+		if err != nil {
+			return sbom.Document{}, err
+		}
+		doc.AddPackages(ecosystem, database)
+	}
+	return doc, nil
+}
+
+func readSingleFile(archive []byte) (string, error) {
+	reader := tar.NewReader(bytes.NewReader(archive))
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return "", nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("read docker cp archive: %w", err)
+		}
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(reader)
+		if err != nil {
+			return "", fmt.Errorf("read docker cp archive: %w", err)
+		}
+		return string(data), nil
+	}
+}
+
+func sbomPath(projectPath, name, imageTag string) string {
+	return filepath.Join(projectPath, sbomDir, fmt.Sprintf("%s-%s.cdx.json", name, tagSuffix(imageTag)))
+}
@@ -0,0 +1,120 @@
+#!/bin/sh
+# Prepares a host for airo deploys. Every step is idempotent and reports
+# "changed: ..." or "ok: ..." so the caller can summarize what happened.
+set -eu
+
+SUDO=""
+if [ "$(id -u)" -ne 0 ]; then
+	if ! command -v sudo >/dev/null 2>&1; then
+		echo "error: run as root or install sudo" >&2
+		exit 1
+	fi
+	SUDO="sudo"
+fi
+
+[ -r /etc/os-release ] && . /etc/os-release
+DISTRO="${ID:-unknown}"
+DISTRO_LIKE="${ID_LIKE:-}"
+
+install_packages() {
+	case "$DISTRO $DISTRO_LIKE" in
+	*debian* | *ubuntu*)
+		$SUDO apt-get update -qq
+		DEBIAN_FRONTEND=noninteractive $SUDO apt-get install -y -qq "$@"
+		;;
+	*fedora* | *rhel* | *centos* | *rocky* | *almalinux*)
+		$SUDO dnf install -y -q "$@"
+		;;
+	*alpine*)
+		$SUDO apk add --quiet "$@"
+		;;
+	*arch*)
+		$SUDO pacman -Sy --noconfirm --needed "$@"
+		;;
+	*)
+		echo "error: unsupported distribution $DISTRO; install $* manually" >&2
+		exit 1
+		;;
+	esac
+}
+
+if command -v docker >/dev/null 2>&1; then
+	echo "ok: docker is installed"
+else
+	case "$DISTRO $DISTRO_LIKE" in
+	*debian* | *ubuntu*) install_packages docker.io ;;
+	*fedora* | *rhel* | *centos* | *rocky* | *almalinux*) install_packages moby-engine || install_packages docker ;;
+	*) install_packages docker ;;
+	esac
+	echo "changed: installed docker"
+fi
+
+if command -v systemctl >/dev/null 2>&1; then
+	if systemctl is-active --quiet docker; then
+		echo "ok: docker service is running"
+	else
+		$SUDO systemctl enable --now docker >/dev/null
+		echo "changed: enabled and started docker service"
+	fi
+elif command -v rc-service >/dev/null 2>&1; then
+	if rc-service docker status >/dev/null 2>&1; then
+		echo "ok: docker service is running"
+	else
+		$SUDO rc-update add docker default >/dev/null
+		$SUDO rc-service docker start >/dev/null
+		echo "changed: enabled and started docker service"
+	fi
+fi
+
+if [ -n "$AIRO_DEPLOY_USER" ] && [ "$AIRO_DEPLOY_USER" != "root" ]; then
+	if id "$AIRO_DEPLOY_USER" >/dev/null 2>&1; then
+		echo "ok: user $AIRO_DEPLOY_USER exists"
+	else
+		if command -v useradd >/dev/null 2>&1; then
+			$SUDO useradd --create-home --shell /bin/sh "$AIRO_DEPLOY_USER"
+		else
+			$SUDO adduser -D -s /bin/sh "$AIRO_DEPLOY_USER"
+		fi
+		echo "changed: created user $AIRO_DEPLOY_USER"
+	fi
+
+	if id -nG "$AIRO_DEPLOY_USER" | tr ' ' '\n' | grep -qx docker; then
+		echo "ok: user $AIRO_DEPLOY_USER is in the docker group"
+	else
+		if command -v usermod >/dev/null 2>&1; then
+			$SUDO usermod -aG docker "$AIRO_DEPLOY_USER"
+		else
+			$SUDO addgroup "$AIRO_DEPLOY_USER" docker
+		fi
+		echo "changed: added user $AIRO_DEPLOY_USER to the docker group"
+	fi
+fi
+
+DEPLOY_USER="${AIRO_DEPLOY_USER:-$(id -un)}"
+DEPLOY_HOME="$(getent passwd "$DEPLOY_USER" | cut -d: -f6)"
+DEPLOY_HOME="${DEPLOY_HOME:-$HOME}"
+
+if [ -n "$AIRO_PUBLIC_KEY" ]; then
+	KEYS="$DEPLOY_HOME/.ssh/authorized_keys"
+	if $SUDO test -f "$KEYS" && $SUDO grep -qxF "$AIRO_PUBLIC_KEY" "$KEYS"; then
+		echo "ok: ssh key is authorized for $DEPLOY_USER"
+	else
+		$SUDO mkdir -p "$DEPLOY_HOME/.ssh"
+		printf '%s\n' "$AIRO_PUBLIC_KEY" | $SUDO tee -a "$KEYS" >/dev/null
+		$SUDO chmod 700 "$DEPLOY_HOME/.ssh"
+		$SUDO chmod 600 "$KEYS"
+		$SUDO chown -R "$DEPLOY_USER" "$DEPLOY_HOME/.ssh"
+		echo "changed: authorized ssh key for $DEPLOY_USER"
+	fi
+fi
+
+for dir in $AIRO_ENV_DIRS; do
+	if $SUDO test -d "$dir"; then
+		echo "ok: directory $dir exists"
+	else
+		$SUDO mkdir -p "$dir"
+		$SUDO chown "$DEPLOY_USER" "$dir"
+		$SUDO chmod 700 "$dir"
+		echo "changed: created directory $dir"
+	fi
+done
@@ -0,0 +1,219 @@
+package docker
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"bypirob/airo/src/internal/config"
+)
+
+const remoteSecretsDir = remoteStateDir + "/secrets"
+
+func hasSecrets(container config.ContainerConfig) bool {
+	return container.Secrets.EnvFile != "" || len(container.Secrets.Files) > 0
+}
+
+func DecryptSecret(cfg config.Config, path string) ([]byte, error) {
+	cmd := exec.Command("age", "--decrypt", "--identity", expandUserPath(cfg.Secrets.Identity), path)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("age decrypt %s: %w (%s)", path, err, strings.TrimSpace(stderr.String()))
+	}
+	return output, nil
+}
+
+func EncryptSecret(cfg config.Config, data []byte, path string) error {
+	recipients, err := secretRecipients(cfg)
+	if err != nil {
+		return err
+	}
+
+	args := []string{"--encrypt", "--armor", "--output", path}
+	for _, recipient := range recipients {
+		args = append(args, "--recipient", recipient)
+	}
+
+	cmd := exec.Command("age", args...)
+	cmd.Stdin = bytes.NewReader(data)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("age encrypt %s: %w (%s)", path, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+func EditSecret(cfg config.Config, path string) error {
+	tmp, err := os.CreateTemp("", "airo-secret-*")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	var original []byte
+	if _, err := os.Stat(path); err == nil {
+		original, err = DecryptSecret(cfg, path)
+		if err != nil {
+			tmp.Close()
+			return err
+		}
+	}
+	if _, err := tmp.Write(original); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write temp file: %w", err)
+	}
+
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vi"
+	}
+	cmd := exec.Command("sh", "-c", editor+" "+shellQuote(tmp.Name()))
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor: %w", err)
+	}
+
+	edited, err := os.ReadFile(tmp.Name())
+	if err != nil {
+		return fmt.Errorf("read temp file: %w", err)
+	}
+	if original != nil && bytes.Equal(original, edited) {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create secrets directory: %w", err)
+	}
+	return EncryptSecret(cfg, edited, path)
+}
+
+func GenerateSecretKey(cfg config.Config) (string, error) {
+	identity := expandUserPath(cfg.Secrets.Identity)
+	if _, err := os.Stat(identity); err == nil {
+		return "", fmt.Errorf("identity %s already exists", identity)
+	}
+	if err := os.MkdirAll(filepath.Dir(identity), 0o700); err != nil {
+		return "", fmt.Errorf("create identity directory: %w", err)
+	}
+
+	output, err := exec.Command("age-keygen", "--output", identity).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("age-keygen: %w (%s)", err, strings.TrimSpace(string(output)))
+	}
+	return identityRecipient(identity)
+}
+
+func secretRecipients(cfg config.Config) ([]string, error) {
+	if len(cfg.Secrets.Recipients) > 0 {
+		return cfg.Secrets.Recipients, nil
+	}
+	recipient, err := identityRecipient(expandUserPath(cfg.Secrets.Identity))
+	if err != nil {
+		return nil, err
+	}
+	return []string{recipient}, nil
+}
+
+func identityRecipient(identity string) (string, error) {
+	output, err := exec.Command("age-keygen", "-y", identity).Output()
+	if err != nil {
+		return "", fmt.Errorf("read recipient from %s: %w", identity, err)
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func deliverSecrets(cfg config.Config, projectPath string, container config.ContainerConfig) error {
+	type upload struct {
+		name   string
+		source string
+		data   []byte
+	}
+	uploads := []upload{}
+	if container.Secrets.EnvFile != "" {
+		uploads = append(uploads, upload{name: "env", source: container.Secrets.EnvFile})
+	}
+	for i, secret := range container.Secrets.Files {
+		uploads = append(uploads, upload{name: fmt.Sprintf("files/%d", i), source: secret.Source})
+	}
+	for i := range uploads {
+		source := uploads[i].source
+		if !filepath.IsAbs(source) {
+			source = filepath.Join(projectPath, source)
+		}
+		data, err := DecryptSecret(cfg, source)
+		if err != nil {
+			return err
+		}
+		uploads[i].data = data
+	}
+
+	dir := remoteSecretsDir + "/" + container.Name
+	staging := dir + ".new"
+	prepare := fmt.Sprintf("set -e; umask 077; rm -rf %s; mkdir -p %s/files", shellQuote(staging), shellQuote(staging))
+	if err := sshShell(cfg, prepare).Run(); err != nil {
+		return fmt.Errorf("ssh prepare secrets (%s): %w", container.Name, err)
+	}
+
+	for _, item := range uploads {
+		cmd := sshShell(cfg, fmt.Sprintf("umask 077; cat > %s", shellQuote(staging+"/"+item.name)))
+		cmd.Stdin = bytes.NewReader(item.data)
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return fmt.Errorf("ssh upload secret %s (%s): %w", filepath.Base(item.source), container.Name, err)
+		}
+	}
+
+	steps := []string{"set -e"}
+	for i, secret := range container.Secrets.Files {
+		path := shellQuote(fmt.Sprintf("%s/files/%d", staging, i))
+		if secret.Mode != "" {
+			steps = append(steps, fmt.Sprintf("chmod %s %s", secret.Mode, path))
+		}
+		if secret.Owner != "" {
+			steps = append(steps, fmt.Sprintf("{ chown %s %s 2>/dev/null || sudo -n chown %s %s; }", secret.Owner, path, secret.Owner, path))
+		}
+	}
+	steps = append(steps,
+		fmt.Sprintf("rm -rf %s.old", shellQuote(dir)),
+		fmt.Sprintf("if [ -d %s ]; then mv %s %s.old; fi", shellQuote(dir), shellQuote(dir), shellQuote(dir)),
+		fmt.Sprintf("mv %s %s", shellQuote(staging), shellQuote(dir)),
+		fmt.Sprintf("rm -rf %s.old", shellQuote(dir)),
+	)
+	cmd := sshShell(cfg, strings.Join(steps, "; "))
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ssh install secrets (%s): %w", container.Name, err)
+	}
+	return nil
+}
+
+func secretRunArgs(cfg config.Config, container config.ContainerConfig) ([]string, error) {
+	if !hasSecrets(container) {
+		return nil, nil
+	}
+
+	output, err := sshShell(cfg, "echo \"$HOME\"").Output()
+	if err != nil {
+		return nil, fmt.Errorf("ssh resolve home: %w", err)
+	}
+	dir := fmt.Sprintf("%s/%s/%s", strings.TrimSpace(string(output)), remoteSecretsDir, container.Name)
+
+	args := make([]string, 0)
+	if container.Secrets.EnvFile != "" {
+		args = append(args, "--env-file", dir+"/env")
+	}
+	for i, secret := range container.Secrets.Files {
+		args = append(args, "-v", fmt.Sprintf("%s/files/%d:%s:ro", dir, i, secret.Target))
+	}
+	return args, nil
+}
@@ -0,0 +1,82 @@
+package docker
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"os"
+	"path"
+	"slices"
+	"strings"
+
+	"bypirob/airo/src/internal/config"
+)
+
+//go:embed scripts/setup.sh
+var setupScript string
+
+type SetupOptions struct {
+	DeployUser    string
+	PublicKeyPath string
+}
+
+type SetupStep struct {
+	Message string
+	Changed bool
+}
+
+func DefaultPublicKey(cfg config.Config) string {
+	if cfg.Deploy.SSH.IdentityFile == "" {
+		return ""
+	}
+	path := cfg.Deploy.SSH.IdentityFile + ".pub"
+	if _, err := os.Stat(expandUserPath(path)); err != nil {
+		return ""
+	}
+	return path
+}
+
+func SetupServer(cfg config.Config, opts SetupOptions) ([]SetupStep, error) {
+	publicKey := ""
+	if opts.PublicKeyPath != "" {
+		data, err := os.ReadFile(expandUserPath(opts.PublicKeyPath))
+		if err != nil {
+			return nil, fmt.Errorf("read public key: %w", err)
+		}
+		publicKey = strings.TrimSpace(string(data))
+	}
+
+	envDirs := make([]string, 0)
+	for _, container := range cfg.Deploy.Containers {
+		if container.EnvFile == "" {
+			continue
+		}
+		dir := path.Dir(container.EnvFile)
+		if dir != "." && dir != "/" && !slices.Contains(envDirs, dir) {
+			envDirs = append(envDirs, dir)
+		}
+	}
+
+	vars := fmt.Sprintf("AIRO_DEPLOY_USER=%s AIRO_PUBLIC_KEY=%s AIRO_ENV_DIRS=%s",
+		shellQuote(opts.DeployUser), shellQuote(publicKey), shellQuote(strings.Join(envDirs, " ")))
+	sshCmd := sshCommand(cfg, vars, "sh", "-s")
+	sshCmd.Stdin = strings.NewReader(setupScript)
+	sshCmd.Stderr = os.Stderr
+
+	output, err := sshCmd.Output()
+	steps := make([]SetupStep, 0)
+	scanner := bufio.NewScanner(strings.NewReader(string(output)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if message, ok := strings.CutPrefix(line, "changed: "); ok {
+			steps = append(steps, SetupStep{Message: message, Changed: true})
+		} else if message, ok := strings.CutPrefix(line, "ok: "); ok {
+			steps = append(steps, SetupStep{Message: message})
+		}
+	}
+	if err != nil {
+		return steps, fmt.Errorf("ssh setup: %w", err)
+	}
+
+	return steps, nil
+}
@@ -29,6 +29,10 @@ func sshCommand(cfg config.Config, remoteArgs ...string) *exec.Cmd {
 	return exec.Command("ssh", args...)
 }
 
+func sshShell(cfg config.Config, script string) *exec.Cmd {
+	return sshCommand(cfg, "sh", "-c", shellQuote(script))
+}
+
 func expandUserPath(path string) string {
 	if strings.HasPrefix(path, "~/") {
 		home, err := os.UserHomeDir()
@@ -1,6 +1,7 @@
 package docker
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -54,3 +55,47 @@ func dockerStatus(cfg config.Config, name string, all bool) (string, error) {
 
 	return strings.TrimSpace(string(output)), nil
 }
+
+type ContainerInfo struct {
+	Name   string
+	Image  string
+	Status string
+	Labels map[string]string
+}
+
+func ContainerDetails(cfg config.Config) ([]ContainerInfo, error) {
+	names := make([]string, 0, len(cfg.Deploy.Containers))
+	for _, container := range cfg.Deploy.Containers {
+		names = append(names, container.Name)
+	}
+
+	format := "{{.Name}}\t{{.Config.Image}}\t{{.State.Status}}\t{{json .Config.Labels}}"
+	script := fmt.Sprintf("docker inspect --format %s %s 2>/dev/null || true", shellQuote(format), shellJoin(names))
+	output, err := sshShell(cfg, script).Output()
+	if err != nil {
+		return nil, fmt.Errorf("ssh inspect containers: %w", err)
+	}
+
+	found := make(map[string]ContainerInfo, len(names))
+	for _, line := range strings.Split(strings.TrimSpace(string(output)), "\n") {
+		fields := strings.SplitN(line, "\t", 4)
+		if len(fields) < 4 {
+			continue
+		}
+		info := ContainerInfo{Name: strings.TrimPrefix(fields[0], "/"), Image: fields[1], Status: fields[2]}
+		if err := json.Unmarshal([]byte(fields[3]), &info.Labels); err != nil {
+			return nil, fmt.Errorf("parse labels (%s): %w", info.Name, err)
+		}
+		found[info.Name] = info
+	}
+
+	details := make([]ContainerInfo, 0, len(names))
+	for _, name := range names {
+		info, ok := found[name]
+		if !ok {
+			info = ContainerInfo{Name: name, Status: "not found"}
+		}
+		details = append(details, info)
+	}
+	return details, nil
+}
@@ -1,9 +1,12 @@
 package docker
 
 import (
+	"encoding/json"
 	"fmt"
-	"os/exec"
+	"os"
+	"path/filepath"
 	"strings"
+	"text/template"
 	"time"
 
 	"bypirob/airo/src/internal/config"
@@ -18,7 +21,7 @@ func resolveTags(cfg config.Config, projectPath, tag string) (map[string]string,
 	}
 
 	if tag == "" {
-		suffix, err := defaultTagSuffix(projectPath)
+		suffix, err := defaultTagSuffix(cfg, projectPath)
 		if err != nil {
 			return nil, err
 		}
@@ -58,32 +61,105 @@ func tagSuffix(tag string) string {
 	return tag
 }
 
-func defaultTagSuffix(projectPath string) (string, error) {
-	sha, err := gitShortSHA(projectPath)
-	if err != nil {
-		return "", err
-	}
+type tagFields struct {
+	SHA       string
+	Branch    string
+	Timestamp string
+	Describe  string
+	Version   string
+}
 
+func defaultTagSuffix(cfg config.Config, projectPath string) (string, error) {
 	timestamp := time.Now().UTC().Format("20060102-1504")
-	return fmt.Sprintf("%s-%s", timestamp, sha), nil
-}
+	sha, shaErr := gitShortSHA(projectPath)
 
-func DefaultTagSuffix(projectPath string) (string, error) {
-	return defaultTagSuffix(projectPath)
+	var suffix string
+	switch cfg.TagStrategy {
+	case config.TagStrategyGitDescribe:
+		describe, err := gitDescribe(projectPath)
+		if err != nil {
+			suffix = timestamp
+			break
+		}
+		suffix = describe
+	case config.TagStrategyGitTag:
+		tag, err := gitExactTag(projectPath)
+		if err != nil {
+			return "", err
+		}
+		suffix = tag
+	case config.TagStrategySemver:
+		version, err := projectVersion(projectPath)
+		if err != nil {
+			return "", err
+		}
+		suffix = version
+	case config.TagStrategyTemplate:
+		tmpl, err := template.New("tag_template").Option("missingkey=error").Parse(cfg.TagTemplate)
+		if err != nil {
+			return "", fmt.Errorf("parse tag_template: %w", err)
+		}
+		fields := tagFields{SHA: sha, Branch: gitBranch(projectPath), Timestamp: timestamp}
+		fields.Describe, _ = gitDescribe(projectPath)
+		fields.Version, _ = projectVersion(projectPath)
+		var b strings.Builder
+		if err := tmpl.Execute(&b, fields); err != nil {
+			return "", fmt.Errorf("render tag_template: %w", err)
+		}
+		suffix = b.String()
+	default:
+		suffix = timestamp
+		if shaErr == nil {
+			suffix = fmt.Sprintf("%s-%s", timestamp, sha)
+		}
+	}
+
+	suffix = sanitizeTag(suffix)
+	if suffix == "" {
+		return "", fmt.Errorf("tag strategy %s produced an empty tag", cfg.TagStrategy)
+	}
+	return suffix, nil
 }
 
-func gitShortSHA(projectPath string) (string, error) {
-	cmd := exec.Command("git", "rev-parse", "--short", "HEAD")
-	cmd.Dir = projectPath
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return "", fmt.Errorf("resolve git commit: %w (%s)", err, strings.TrimSpace(string(output)))
+func DefaultTagSuffix(cfg config.Config, projectPath string) (string, error) {
+	if projectPath == "" {
+		projectPath = "."
 	}
+	return defaultTagSuffix(cfg, projectPath)
+}
 
-	sha := strings.TrimSpace(string(output))
-	if sha == "" {
-		return "", fmt.Errorf("resolve git commit: empty sha")
+func projectVersion(projectPath string) (string, error) {
+	if data, err := os.ReadFile(filepath.Join(projectPath, "package.json")); err == nil {
+		var pkg struct {
+			Version string `json:"version"`
+		}
+		if err := json.Unmarshal(data, &pkg); err != nil {
+			return "", fmt.Errorf("parse package.json: %w", err)
+		}
+		if pkg.Version != "" {
+			return pkg.Version, nil
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(projectPath, "VERSION")); err == nil {
+		if version := strings.TrimSpace(string(data)); version != "" {
+			return version, nil
+		}
 	}
+	return "", fmt.Errorf("no version found in package.json or VERSION")
+}
 
-	return sha, nil
+func sanitizeTag(tag string) string {
+	sanitized := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '.', r == '-':
+			return r
+		default:
+			return '-'
+		}
+	}, tag)
+	sanitized = strings.Trim(sanitized, ".-")
+	if len(sanitized) > 128 {
+		sanitized = sanitized[:128]
+	}
+	return sanitized
 }
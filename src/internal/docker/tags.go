@@ -2,10 +2,12 @@ package docker
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
-	"os/exec"
+	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"bypirob/airo/src/internal/config"
@@ -16,76 +18,234 @@ type TagsResult struct {
 	Tags []string `json:"tags"`
 }
 
-func Tags(cfg config.Config, remote bool) ([]string, error) {
-	if remote {
-		return remoteTags(cfg)
+const (
+	TagSourceLocal    = "local"
+	TagSourceHost     = "host"
+	TagSourceRegistry = "registry"
+)
+
+func Tags(cfg config.Config, source string) ([]Image, error) {
+	switch source {
+	case TagSourceHost:
+		return listHostImages(cfg)
+	case TagSourceRegistry:
+		return listRegistryImages(cfg)
+	default:
+		return listLocalImages(cfg)
 	}
-	return localTags(cfg)
 }
 
-func localTags(cfg config.Config) ([]string, error) {
-	cmd := exec.Command("docker", "images", "--format", "{{.Repository}}:{{.Tag}}")
-	output, err := cmd.Output()
+func DeployedTags(cfg config.Config) (map[string][]string, error) {
+	deployed, err := deployedImages(cfg)
 	if err != nil {
-		return nil, fmt.Errorf("docker images: %w", err)
+		return nil, err
 	}
 
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	tags := make([]string, 0, len(lines))
-	for name := range cfg.Images {
-		repoPrefix := fmt.Sprintf("%s:", name)
-		for _, line := range lines {
-			if line == "" || !strings.HasPrefix(line, repoPrefix) {
-				continue
-			}
-			tag := strings.TrimPrefix(line, repoPrefix)
-			if tag == "" || tag == "<none>" {
-				continue
-			}
-			tags = append(tags, fmt.Sprintf("%s:%s", name, tag))
+	containers := make(map[string][]string, len(deployed))
+	for _, container := range cfg.Deploy.Containers {
+		if ref, ok := deployed[container.Name]; ok {
+			containers[ref] = append(containers[ref], container.Name)
 		}
 	}
+	return containers, nil
+}
 
-	return tags, nil
+type skippedTag struct {
+	Tag string
+	Err error
 }
 
-func remoteTags(cfg config.Config) ([]string, error) {
-	if cfg.Deploy.Registry.RegistryURL == "" {
-		return nil, fmt.Errorf("deploy.registry.registry_url is required for remote tags")
+func (s skippedTag) Missing() bool {
+	var regErr *registryError
+	return errors.As(s.Err, &regErr) && regErr.StatusCode == http.StatusNotFound
+}
+
+func listRegistryImages(cfg config.Config) ([]Image, error) {
+	images, skipped, err := registryImages(cfg)
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range skipped {
+		fmt.Fprintf(os.Stderr, "Warning: skipping registry tag %s: %v\n", tag.Tag, tag.Err)
 	}
+	return images, nil
+}
 
-	base := strings.TrimSuffix(cfg.Deploy.Registry.RegistryURL, "/")
-	if !strings.HasPrefix(base, "http://") && !strings.HasPrefix(base, "https://") {
-		base = "https://" + base
+func registryImages(cfg config.Config) ([]Image, []skippedTag, error) {
+	client, err := newRegistryClient(cfg)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	url := fmt.Sprintf("%s/v2/%s/tags/list", base, cfg.Deploy.Registry.Repository)
-	resp, err := client.Get(url)
+	resp, err := client.do(http.MethodGet, fmt.Sprintf("/v2/%s/tags/list", client.repository), "")
 	if err != nil {
-		return nil, fmt.Errorf("fetch tags: %w", err)
+		return nil, nil, fmt.Errorf("fetch tags: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode < 200 || resp.StatusCode > 299 {
-		return nil, fmt.Errorf("registry tags request failed: %s", resp.Status)
+		return nil, nil, &registryError{Request: "tags", StatusCode: resp.StatusCode, Status: resp.Status}
 	}
 
 	var result TagsResult
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("parse tags response: %w", err)
+		return nil, nil, fmt.Errorf("parse tags response: %w", err)
 	}
 
-	tags := make([]string, 0)
-	for name := range cfg.Images {
+	candidates := make([]Image, 0)
+	for _, name := range cfg.ImageNames() {
 		prefix := name + "-"
 		for _, tag := range result.Tags {
-			if !strings.HasPrefix(tag, prefix) {
-				continue
+			if strings.HasPrefix(tag, prefix) {
+				candidates = append(candidates, Image{Name: name, Tag: strings.TrimPrefix(tag, prefix)})
 			}
-			tags = append(tags, fmt.Sprintf("%s:%s", name, strings.TrimPrefix(tag, prefix)))
 		}
 	}
 
-	return tags, nil
+	errs := make([]error, len(candidates))
+	var wg sync.WaitGroup
+	slots := make(chan struct{}, registryConcurrency)
+	for i := range candidates {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			image := &candidates[i]
+			image.Digest, image.Created, errs[i] = client.imageInfo(image.Name + "-" + image.Tag)
+			if errs[i] == nil && image.Created.IsZero() {
+				image.Created = tagTime(image.Tag)
+			}
+		}()
+	}
+	wg.Wait()
+
+	images := make([]Image, 0, len(candidates))
+	skipped := make([]skippedTag, 0)
+	for i, image := range candidates {
+		if errs[i] != nil {
+			skipped = append(skipped, skippedTag{Tag: image.Name + "-" + image.Tag, Err: errs[i]})
+			continue
+		}
+		images = append(images, image)
+	}
+	sortImages(images)
+
+	return images, skipped, nil
+}
+
+var manifestMediaTypes = strings.Join([]string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}, ", ")
+
+type registryManifest struct {
+	Config struct {
+		Digest string `json:"digest"`
+	} `json:"config"`
+	Manifests []struct {
+		Digest   string `json:"digest"`
+		Platform struct {
+			Architecture string `json:"architecture"`
+			OS           string `json:"os"`
+			Variant      string `json:"variant"`
+		} `json:"platform"`
+	} `json:"manifests"`
+}
+
+func (c *registryClient) manifest(reference string) (string, registryManifest, error) {
+	resp, err := c.do(http.MethodGet, fmt.Sprintf("/v2/%s/manifests/%s", c.repository, reference), manifestMediaTypes)
+	if err != nil {
+		return "", registryManifest{}, fmt.Errorf("fetch manifest: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", registryManifest{}, &registryError{Request: "manifest", StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+
+	var manifest registryManifest
+	if err := json.NewDecoder(resp.Body).Decode(&manifest); err != nil {
+		return "", registryManifest{}, fmt.Errorf("parse manifest: %w", err)
+	}
+	return resp.Header.Get("Docker-Content-Digest"), manifest, nil
+}
+
+func (c *registryClient) imageInfo(tag string) (string, time.Time, error) {
+	digest, manifest, err := c.manifest(tag)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if digest == "" {
+		return "", time.Time{}, fmt.Errorf("registry did not return a manifest digest")
+	}
+	for _, entry := range manifest.Manifests {
+		if entry.Platform.OS == "unknown" {
+			continue
+		}
+		if _, manifest, err = c.manifest(entry.Digest); err != nil {
+			return "", time.Time{}, err
+		}
+		break
+	}
+	if manifest.Config.Digest == "" {
+		return digest, time.Time{}, nil
+	}
+
+	resp, err := c.do(http.MethodGet, fmt.Sprintf("/v2/%s/blobs/%s", c.repository, manifest.Config.Digest), "")
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("fetch image config: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", time.Time{}, &registryError{Request: "blob", StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+	var imageConfig struct {
+		Created time.Time `json:"created"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&imageConfig); err != nil {
+		return "", time.Time{}, fmt.Errorf("parse image config: %w", err)
+	}
+	return digest, imageConfig.Created, nil
+}
+
+func deleteRegistryImage(cfg config.Config, image Image) error {
+	client, err := newRegistryClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	digest := image.Digest
+	if digest == "" {
+		if digest, _, err = client.manifest(image.Name + "-" + image.Tag); err != nil {
+			return err
+		}
+		if digest == "" {
+			return fmt.Errorf("registry did not return a manifest digest")
+		}
+	}
+
+	resp, err := client.do(http.MethodDelete, fmt.Sprintf("/v2/%s/manifests/%s", client.repository, digest), "")
+	if err != nil {
+		return fmt.Errorf("delete manifest: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return &registryError{Request: "delete", StatusCode: resp.StatusCode, Status: resp.Status}
+	}
+	return nil
+}
+
+func registryBase(cfg config.Config) (string, error) {
+	if cfg.Deploy.Registry.RegistryURL == "" {
+		return "", fmt.Errorf("deploy.registry.registry_url is required for registry requests")
+	}
+
+	base := strings.TrimSuffix(cfg.Deploy.Registry.RegistryURL, "/")
+	if !strings.HasPrefix(base, "http://") && !strings.HasPrefix(base, "https://") {
+		base = "https://" + base
+	}
+	return base, nil
 }
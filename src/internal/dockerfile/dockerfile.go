@@ -0,0 +1,341 @@
+package dockerfile
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	StackNode   = "node"
+	StackGo     = "go"
+	StackPython = "python"
+	StackStatic = "static"
+)
+
+type Stack struct {
+	Name           string
+	PackageManager string
+	Lockfile       string
+	YarnBerry      bool
+	YarnRC         bool
+	YarnDir        bool
+	HasBuild       bool
+	HasStart       bool
+	Main           string
+	GoSum          bool
+	GoPackage      string
+	Requirements   bool
+	Pyproject      bool
+	Entrypoint     string
+}
+
+func Detect(projectPath string) (Stack, error) {
+	switch {
+	case exists(projectPath, "package.json"):
+		return detectNode(projectPath)
+	case exists(projectPath, "go.mod"):
+		return detectGo(projectPath), nil
+	case exists(projectPath, "pyproject.toml"), exists(projectPath, "requirements.txt"):
+		return detectPython(projectPath), nil
+	case exists(projectPath, "index.html"):
+		return Stack{Name: StackStatic}, nil
+	default:
+		return Stack{}, fmt.Errorf("no Dockerfile and no package.json, go.mod, pyproject.toml, requirements.txt or index.html in %s", projectPath)
+	}
+}
+
+func (s Stack) DefaultImage() string {
+	switch s.Name {
+	case StackGo:
+		return "golang:1.25-alpine"
+	case StackPython:
+		return "python:3.13-slim"
+	case StackStatic:
+		return "caddy:2-alpine"
+	default:
+		return "node:24-alpine"
+	}
+}
+
+func (s Stack) DefaultRuntimeImage() string {
+	if s.Name == StackGo {
+		return "alpine:3.22"
+	}
+	return ""
+}
+
+func (s Stack) DefaultPort() int {
+	switch s.Name {
+	case StackGo:
+		return 8080
+	case StackPython:
+		return 8000
+	case StackStatic:
+		return 80
+	default:
+		return 3000
+	}
+}
+
+func (s Stack) String() string {
+	if s.PackageManager != "" {
+		return fmt.Sprintf("%s (%s)", s.Name, s.PackageManager)
+	}
+	return s.Name
+}
+
+func (s Stack) Dockerfile(baseImage, runtimeImage string, port int) string {
+	if baseImage == "" {
+		baseImage = s.DefaultImage()
+	}
+	if runtimeImage == "" {
+		runtimeImage = s.DefaultRuntimeImage()
+	}
+	if runtimeImage == "" {
+		runtimeImage = baseImage
+	}
+	if port == 0 {
+		port = s.DefaultPort()
+	}
+
+	var lines []string
+	switch s.Name {
+	case StackNode:
+		lines = s.node(baseImage, runtimeImage)
+	case StackGo:
+		lines = s.golang(baseImage, runtimeImage)
+	case StackPython:
+		lines = s.python(baseImage, runtimeImage)
+	case StackStatic:
+		lines = []string{
+			"FROM " + runtimeImage,
+			"COPY . /srv",
+		}
+	}
+
+	lines = append(lines,
+		fmt.Sprintf("ENV PORT=%d", port),
+		fmt.Sprintf("EXPOSE %d", port),
+		"CMD "+execForm(s.command(port)),
+	)
+	return fmt.Sprintf("# Generated by airo for a %s project\n", s) + strings.Join(lines, "\n") + "\n"
+}
+
+func (s Stack) node(baseImage, runtimeImage string) []string {
+	corepack := ""
+	if s.PackageManager != "npm" {
+		corepack = "corepack enable && "
+	}
+	install := "npm install"
+	switch {
+	case s.PackageManager == "pnpm":
+		install = "pnpm install --frozen-lockfile"
+	case s.PackageManager == "yarn" && s.YarnBerry:
+		install = "yarn install --immutable"
+	case s.PackageManager == "yarn":
+		install = "yarn install --frozen-lockfile"
+	case s.Lockfile != "":
+		install = "npm ci"
+	}
+	manifests := "package.json"
+	if s.Lockfile != "" {
+		manifests += " " + s.Lockfile
+	}
+	if s.YarnRC {
+		manifests += " .yarnrc.yml"
+	}
+	dependencies := "COPY --from=deps /app/node_modules ./node_modules"
+	if s.YarnBerry {
+		dependencies = "COPY --from=deps /app ./"
+	}
+
+	lines := []string{
+		"FROM " + baseImage + " AS deps",
+		"WORKDIR /app",
+		"COPY " + manifests + " ./",
+	}
+	if s.YarnDir {
+		lines = append(lines, "COPY .yarn ./.yarn")
+	}
+	lines = append(lines,
+		"RUN "+corepack+install,
+		"",
+		"FROM "+baseImage+" AS build",
+		"WORKDIR /app",
+		"COPY . .",
+		dependencies,
+	)
+	if s.HasBuild {
+		lines = append(lines, "RUN "+corepack+s.PackageManager+" run build")
+	}
+	lines = append(lines,
+		"",
+		"FROM "+runtimeImage,
+		"WORKDIR /app",
+		"ENV NODE_ENV=production",
+	)
+	if corepack != "" && s.HasStart {
+		lines = append(lines, "RUN corepack enable")
+	}
+	return append(lines, "COPY --from=build /app ./")
+}
+
+func (s Stack) golang(baseImage, runtimeImage string) []string {
+	manifests := "go.mod"
+	if s.GoSum {
+		manifests += " go.sum"
+	}
+	return []string{
+		"FROM " + baseImage + " AS build",
+		"WORKDIR /src",
+		"COPY " + manifests + " ./",
+		"RUN go mod download",
+		"COPY . .",
+		"RUN CGO_ENABLED=0 go build -trimpath -ldflags=\"-s -w\" -o /out/app " + s.GoPackage,
+		"",
+		"FROM " + runtimeImage,
+		"COPY --from=build /etc/ssl/certs/ca-certificates.crt /etc/ssl/certs/ca-certificates.crt",
+		"COPY --from=build /out/app /usr/local/bin/app",
+	}
+}
+
+func (s Stack) python(baseImage, runtimeImage string) []string {
+	lines := []string{
+		"FROM " + baseImage + " AS build",
+		"WORKDIR /app",
+		"RUN python -m venv /opt/venv",
+		"ENV PATH=\"/opt/venv/bin:$PATH\"",
+	}
+	if s.Requirements {
+		lines = append(lines,
+			"COPY requirements.txt ./",
+			"RUN pip install --no-cache-dir -r requirements.txt",
+		)
+	}
+	lines = append(lines, "COPY . .")
+	if s.Pyproject {
+		lines = append(lines, "RUN pip install --no-cache-dir .")
+	}
+	return append(lines,
+		"",
+		"FROM "+runtimeImage,
+		"WORKDIR /app",
+		"ENV PATH=\"/opt/venv/bin:$PATH\" PYTHONUNBUFFERED=1",
+		"COPY --from=build /opt/venv /opt/venv",
+		"COPY --from=build /app ./",
+	)
+}
+
+func (s Stack) command(port int) []string {
+	switch s.Name {
+	case StackNode:
+		if s.HasStart {
+			return []string{s.PackageManager, "start"}
+		}
+		return []string{"node", s.Main}
+	case StackGo:
+		return []string{"app"}
+	case StackPython:
+		if s.Entrypoint == "manage.py" {
+			return []string{"python", "manage.py", "runserver", fmt.Sprintf("0.0.0.0:%d", port)}
+		}
+		return []string{"python", s.Entrypoint}
+	default:
+		return []string{"caddy", "file-server", "--root", "/srv", "--listen", fmt.Sprintf(":%d", port)}
+	}
+}
+
+func detectNode(projectPath string) (Stack, error) {
+	stack := Stack{Name: StackNode, PackageManager: "npm", Main: "index.js"}
+	switch {
+	case exists(projectPath, "pnpm-lock.yaml"):
+		stack.PackageManager, stack.Lockfile = "pnpm", "pnpm-lock.yaml"
+	case exists(projectPath, "yarn.lock"):
+		stack.PackageManager, stack.Lockfile = "yarn", "yarn.lock"
+	case exists(projectPath, "package-lock.json"):
+		stack.Lockfile = "package-lock.json"
+	}
+
+	data, err := os.ReadFile(filepath.Join(projectPath, "package.json"))
+	if err != nil {
+		return Stack{}, fmt.Errorf("read package.json: %w", err)
+	}
+	var pkg struct {
+		Main           string            `json:"main"`
+		Scripts        map[string]string `json:"scripts"`
+		PackageManager string            `json:"packageManager"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return Stack{}, fmt.Errorf("parse package.json: %w", err)
+	}
+	manager, version, _ := strings.Cut(pkg.PackageManager, "@")
+	if stack.Lockfile == "" && (manager == "pnpm" || manager == "yarn") {
+		stack.PackageManager = manager
+	}
+	if stack.PackageManager == "yarn" {
+		stack.YarnRC = exists(projectPath, ".yarnrc.yml")
+		if major, _, _ := strings.Cut(version, "."); manager == "yarn" && major != "" {
+			stack.YarnBerry = major != "1"
+		} else {
+			stack.YarnBerry = stack.YarnRC
+		}
+		stack.YarnDir = stack.YarnBerry && exists(projectPath, ".yarn")
+	}
+	stack.HasBuild = pkg.Scripts["build"] != ""
+	stack.HasStart = pkg.Scripts["start"] != ""
+	if pkg.Main != "" {
+		stack.Main = pkg.Main
+	}
+	return stack, nil
+}
+
+func detectGo(projectPath string) Stack {
+	stack := Stack{Name: StackGo, GoSum: exists(projectPath, "go.sum"), GoPackage: "."}
+	if exists(projectPath, "main.go") {
+		return stack
+	}
+	entries, err := os.ReadDir(filepath.Join(projectPath, "cmd"))
+	if err != nil {
+		return stack
+	}
+	commands := []string{}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			commands = append(commands, entry.Name())
+		}
+	}
+	if len(commands) == 1 {
+		stack.GoPackage = "./cmd/" + commands[0]
+	}
+	return stack
+}
+
+func detectPython(projectPath string) Stack {
+	stack := Stack{
+		Name:         StackPython,
+		Requirements: exists(projectPath, "requirements.txt"),
+		Pyproject:    exists(projectPath, "pyproject.toml"),
+		Entrypoint:   "main.py",
+	}
+	for _, candidate := range []string{"main.py", "app.py", "server.py", "manage.py"} {
+		if exists(projectPath, candidate) {
+			stack.Entrypoint = candidate
+			break
+		}
+	}
+	return stack
+}
+
+func execForm(args []string) string {
+	data, _ := json.Marshal(args)
+	return string(data)
+}
+
+func exists(projectPath, name string) bool {
+	_, err := os.Stat(filepath.Join(projectPath, name))
+	return !errors.Is(err, os.ErrNotExist)
+}
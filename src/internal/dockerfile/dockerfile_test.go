@@ -0,0 +1,167 @@
+package dockerfile
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  Stack
+	}{
+		{
+			name:  "npm with lockfile",
+			files: map[string]string{"package.json": `{"scripts":{"build":"vite build","start":"node server.js"}}`, "package-lock.json": "{}"},
+			want:  Stack{Name: StackNode, PackageManager: "npm", Lockfile: "package-lock.json", HasBuild: true, HasStart: true, Main: "index.js"},
+		},
+		{
+			name:  "pnpm",
+			files: map[string]string{"package.json": `{"main":"app.js"}`, "pnpm-lock.yaml": ""},
+			want:  Stack{Name: StackNode, PackageManager: "pnpm", Lockfile: "pnpm-lock.yaml", Main: "app.js"},
+		},
+		{
+			name:  "yarn classic",
+			files: map[string]string{"package.json": `{}`, "yarn.lock": ""},
+			want:  Stack{Name: StackNode, PackageManager: "yarn", Lockfile: "yarn.lock", Main: "index.js"},
+		},
+		{
+			name:  "yarn berry from yarnrc",
+			files: map[string]string{"package.json": `{}`, "yarn.lock": "", ".yarnrc.yml": "nodeLinker: node-modules\n"},
+			want:  Stack{Name: StackNode, PackageManager: "yarn", Lockfile: "yarn.lock", YarnBerry: true, YarnRC: true, Main: "index.js"},
+		},
+		{
+			name:  "yarn berry from packageManager",
+			files: map[string]string{"package.json": `{"packageManager":"yarn@4.5.0"}`, "yarn.lock": "", ".yarn/releases/yarn.cjs": ""},
+			want:  Stack{Name: StackNode, PackageManager: "yarn", Lockfile: "yarn.lock", YarnBerry: true, YarnDir: true, Main: "index.js"},
+		},
+		{
+			name:  "yarn classic pinned despite yarnrc",
+			files: map[string]string{"package.json": `{"packageManager":"yarn@1.22.22"}`, "yarn.lock": "", ".yarnrc.yml": ""},
+			want:  Stack{Name: StackNode, PackageManager: "yarn", Lockfile: "yarn.lock", YarnRC: true, Main: "index.js"},
+		},
+		{
+			name:  "package manager without lockfile",
+			files: map[string]string{"package.json": `{"packageManager":"pnpm@9.0.0"}`},
+			want:  Stack{Name: StackNode, PackageManager: "pnpm", Main: "index.js"},
+		},
+		{
+			name:  "go with single command",
+			files: map[string]string{"go.mod": "module example.com/app\n", "go.sum": "", "cmd/server/main.go": ""},
+			want:  Stack{Name: StackGo, GoSum: true, GoPackage: "./cmd/server"},
+		},
+		{
+			name:  "go main package",
+			files: map[string]string{"go.mod": "module example.com/app\n", "main.go": "", "cmd/a/main.go": ""},
+			want:  Stack{Name: StackGo, GoPackage: "."},
+		},
+		{
+			name:  "python",
+			files: map[string]string{"requirements.txt": "flask\n", "app.py": ""},
+			want:  Stack{Name: StackPython, Requirements: true, Entrypoint: "app.py"},
+		},
+		{
+			name:  "static",
+			files: map[string]string{"index.html": "<html></html>"},
+			want:  Stack{Name: StackStatic},
+		},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir()
+		for name, content := range tt.files {
+			path := filepath.Join(dir, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		got, err := Detect(dir)
+		if err != nil {
+			t.Errorf("%s: Detect() error = %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: Detect() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := Detect(t.TempDir()); err == nil {
+		t.Error("Detect() on an empty directory returned no error")
+	}
+}
+
+func TestDockerfile(t *testing.T) {
+	tests := []struct {
+		name         string
+		stack        Stack
+		baseImage    string
+		runtimeImage string
+		want         []string
+		notWant      []string
+	}{
+		{
+			name:    "npm",
+			stack:   Stack{Name: StackNode, PackageManager: "npm", Lockfile: "package-lock.json", HasStart: true},
+			want:    []string{"FROM node:24-alpine AS deps", "RUN npm ci", "COPY --from=deps /app/node_modules ./node_modules", `CMD ["npm","start"]`, "ENV PORT=3000"},
+			notWant: []string{"corepack"},
+		},
+		{
+			name:  "yarn classic",
+			stack: Stack{Name: StackNode, PackageManager: "yarn", Lockfile: "yarn.lock", HasBuild: true, HasStart: true},
+			want:  []string{"RUN corepack enable && yarn install --frozen-lockfile", "RUN corepack enable && yarn run build", "RUN corepack enable"},
+		},
+		{
+			name:    "yarn berry",
+			stack:   Stack{Name: StackNode, PackageManager: "yarn", Lockfile: "yarn.lock", YarnBerry: true, YarnRC: true, YarnDir: true, Main: "index.js"},
+			want:    []string{"COPY package.json yarn.lock .yarnrc.yml ./", "COPY .yarn ./.yarn", "RUN corepack enable && yarn install --immutable", "COPY --from=deps /app ./", `CMD ["node","index.js"]`},
+			notWant: []string{"--frozen-lockfile"},
+		},
+		{
+			name:         "node runtime image",
+			stack:        Stack{Name: StackNode, PackageManager: "npm", Main: "index.js"},
+			baseImage:    "node:24",
+			runtimeImage: "node:24-slim",
+			want:         []string{"FROM node:24 AS build", "FROM node:24-slim\n", "RUN npm install"},
+		},
+		{
+			name:  "go default runtime",
+			stack: Stack{Name: StackGo, GoSum: true, GoPackage: "./cmd/server"},
+			want:  []string{"FROM golang:1.25-alpine AS build", "COPY go.mod go.sum ./", "-o /out/app ./cmd/server", "FROM alpine:3.22\n", "COPY --from=build /etc/ssl/certs/ca-certificates.crt", "ENV PORT=8080"},
+		},
+		{
+			name:         "go distroless runtime",
+			stack:        Stack{Name: StackGo, GoPackage: "."},
+			runtimeImage: "gcr.io/distroless/static-debian12",
+			want:         []string{"FROM gcr.io/distroless/static-debian12\n"},
+			notWant:      []string{"alpine:3.22", "apk add"},
+		},
+		{
+			name:  "python manage.py",
+			stack: Stack{Name: StackPython, Requirements: true, Entrypoint: "manage.py"},
+			want:  []string{"FROM python:3.13-slim AS build", "RUN pip install --no-cache-dir -r requirements.txt", `CMD ["python","manage.py","runserver","0.0.0.0:8000"]`},
+		},
+		{
+			name:  "static",
+			stack: Stack{Name: StackStatic},
+			want:  []string{"FROM caddy:2-alpine", "COPY . /srv", `CMD ["caddy","file-server","--root","/srv","--listen",":80"]`},
+		},
+	}
+	for _, tt := range tests {
+		got := tt.stack.Dockerfile(tt.baseImage, tt.runtimeImage, 0)
+		for _, want := range tt.want {
+			if !strings.Contains(got, want) {
+				t.Errorf("%s: Dockerfile() missing %q in\n%s", tt.name, want, got)
+			}
+		}
+		for _, notWant := range tt.notWant {
+			if strings.Contains(got, notWant) {
+				t.Errorf("%s: Dockerfile() contains %q in\n%s", tt.name, notWant, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package envfile
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Entry struct {
+	Key   string
+	Value string
+}
+
+type File struct {
+	lines []string
+}
+
+func Parse(data string) File {
+	data = strings.TrimSuffix(data, "\n")
+	if data == "" {
+		return File{}
+	}
+	return File{lines: strings.Split(data, "\n")}
+}
+
+func (f File) Entries() []Entry {
+	entries := make([]Entry, 0, len(f.lines))
+	for _, line := range f.lines {
+		if entry, ok := parseLine(line); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+func (f File) Get(key string) (string, bool) {
+	for _, entry := range f.Entries() {
+		if entry.Key == key {
+			return entry.Value, true
+		}
+	}
+	return "", false
+}
+
+func (f *File) Set(key, value string) {
+	line := fmt.Sprintf("%s=%s", key, value)
+	for i, existing := range f.lines {
+		if entry, ok := parseLine(existing); ok && entry.Key == key {
+			f.lines[i] = line
+			return
+		}
+	}
+	f.lines = append(f.lines, line)
+}
+
+func (f *File) Unset(key string) bool {
+	lines := f.lines[:0]
+	removed := false
+	for _, line := range f.lines {
+		if entry, ok := parseLine(line); ok && entry.Key == key {
+			removed = true
+			continue
+		}
+		lines = append(lines, line)
+	}
+	f.lines = lines
+	return removed
+}
+
+func (f File) String() string {
+	if len(f.lines) == 0 {
+		return ""
+	}
+	return strings.Join(f.lines, "\n") + "\n"
+}
+
+type Change struct {
+	Key  string
+	Kind string
+}
+
+const (
+	Added   = "added"
+	Changed = "changed"
+	Removed = "removed"
+)
+
+func Diff(before, after File) []Change {
+	old := make(map[string]string)
+	for _, entry := range before.Entries() {
+		old[entry.Key] = entry.Value
+	}
+
+	changes := make([]Change, 0)
+	seen := make(map[string]struct{})
+	for _, entry := range after.Entries() {
+		seen[entry.Key] = struct{}{}
+		value, ok := old[entry.Key]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Key: entry.Key, Kind: Added})
+		case value != entry.Value:
+			changes = append(changes, Change{Key: entry.Key, Kind: Changed})
+		}
+	}
+	for _, entry := range before.Entries() {
+		if _, ok := seen[entry.Key]; !ok {
+			changes = append(changes, Change{Key: entry.Key, Kind: Removed})
+			seen[entry.Key] = struct{}{}
+		}
+	}
+	return changes
+}
+
+func Mask(value string) string {
+	if value == "" {
+		return ""
+	}
+	return "********"
+}
+
+func ValidKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for i, r := range key {
+		switch {
+		case r == '_', r >= 'A' && r <= 'Z', r >= 'a' && r <= 'z':
+		case r >= '0' && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func parseLine(line string) (Entry, bool) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return Entry{}, false
+	}
+	trimmed = strings.TrimPrefix(trimmed, "export ")
+	key, value, ok := strings.Cut(trimmed, "=")
+	if !ok {
+		return Entry{Key: strings.TrimSpace(key)}, true
+	}
+	return Entry{Key: strings.TrimSpace(key), Value: value}, true
+}
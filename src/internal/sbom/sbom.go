@@ -0,0 +1,95 @@
+package sbom
+
+import (
+	"bufio"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type Document struct {
+	BOMFormat   string      `json:"bomFormat"`
+	SpecVersion string      `json:"specVersion"`
+	Version     int         `json:"version"`
+	Metadata    Metadata    `json:"metadata"`
+	Components  []Component `json:"components"`
+}
+
+type Metadata struct {
+	Timestamp string    `json:"timestamp"`
+	Component Component `json:"component"`
+}
+
+type Component struct {
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	PURL    string `json:"purl,omitempty"`
+}
+
+const (
+	EcosystemAPK = "apk"
+	EcosystemDeb = "deb"
+)
+
+var PackageDatabases = map[string]string{
+	EcosystemAPK: "/lib/apk/db/installed",
+	EcosystemDeb: "/var/lib/dpkg/status",
+}
+
+func New(imageRef string) Document {
+	return Document{
+		BOMFormat:   "CycloneDX",
+		SpecVersion: "1.5",
+		Version:     1,
+		Metadata: Metadata{
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Component: Component{Type: "container", Name: imageRef},
+		},
+		Components: []Component{},
+	}
+}
+
+func (d *Document) AddPackages(ecosystem, database string) {
+	for _, pkg := range parsePackages(ecosystem, database) {
+		pkg.PURL = fmt.Sprintf("pkg:%s/%s@%s", ecosystem, pkg.Name, pkg.Version)
+		d.Components = append(d.Components, pkg)
+	}
+}
+
+func (c Component) Ecosystem() string {
+	ecosystem, _, _ := strings.Cut(strings.TrimPrefix(c.PURL, "pkg:"), "/")
+	return ecosystem
+}
+
+func parsePackages(ecosystem, database string) []Component {
+	nameKey, versionKey := "P:", "V:"
+	if ecosystem == EcosystemDeb {
+		nameKey, versionKey = "Package: ", "Version: "
+	}
+
+	packages := make([]Component, 0)
+	current := Component{Type: "library"}
+	flush := func() {
+		if current.Name != "" && current.Version != "" {
+			packages = append(packages, current)
+		}
+		current = Component{Type: "library"}
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(database))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.TrimSpace(line) == "":
+			flush()
+		case strings.HasPrefix(line, nameKey):
+			current.Name = strings.TrimPrefix(line, nameKey)
+		case strings.HasPrefix(line, versionKey):
+			current.Version = strings.TrimPrefix(line, versionKey)
+		}
+	}
+	flush()
+	return packages
+}
@@ -0,0 +1,193 @@
+package sbom
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+var Severities = []string{"low", "medium", "high", "critical"}
+
+type Vulnerability struct {
+	ID        string   `json:"id"`
+	Ecosystem string   `json:"ecosystem"`
+	Package   string   `json:"package"`
+	Versions  []string `json:"versions"`
+	Fixed     string   `json:"fixed"`
+	Severity  string   `json:"severity"`
+	Summary   string   `json:"summary"`
+}
+
+type Finding struct {
+	Vulnerability
+	Image            string `json:"image"`
+	InstalledVersion string `json:"installed_version"`
+}
+
+type Report struct {
+	Images   []string  `json:"images"`
+	FailOn   string    `json:"fail_on"`
+	Findings []Finding `json:"findings"`
+	Empty    []string  `json:"empty,omitempty"`
+	Blocked  bool      `json:"blocked"`
+}
+
+func LoadDatabase(path string) ([]Vulnerability, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read vulnerability database: %w", err)
+	}
+	var vulns []Vulnerability
+	if err := json.Unmarshal(data, &vulns); err != nil {
+		return nil, fmt.Errorf("parse vulnerability database %s: %w", path, err)
+	}
+	return vulns, nil
+}
+
+func Scan(docs []Document, vulns []Vulnerability, failOn string) Report {
+	report := Report{FailOn: failOn, Findings: []Finding{}}
+	threshold := SeverityRank(failOn)
+	for _, doc := range docs {
+		report.Images = append(report.Images, doc.Metadata.Component.Name)
+		if len(doc.Components) == 0 {
+			report.Empty = append(report.Empty, doc.Metadata.Component.Name)
+			if threshold >= 0 {
+				report.Blocked = true
+			}
+			continue
+		}
+		for _, component := range doc.Components {
+			for _, vuln := range vulns {
+				if vuln.Package != component.Name || (vuln.Ecosystem != "" && vuln.Ecosystem != component.Ecosystem()) {
+					continue
+				}
+				if !affected(vuln, component.Version) {
+					continue
+				}
+				report.Findings = append(report.Findings, Finding{
+					Vulnerability:    vuln,
+					Image:            doc.Metadata.Component.Name,
+					InstalledVersion: component.Version,
+				})
+				if threshold >= 0 && SeverityRank(vuln.Severity) >= threshold {
+					report.Blocked = true
+				}
+			}
+		}
+	}
+	return report
+}
+
+func SeverityRank(severity string) int {
+	return slices.Index(Severities, strings.ToLower(severity))
+}
+
+func affected(vuln Vulnerability, version string) bool {
+	if slices.Contains(vuln.Versions, version) {
+		return true
+	}
+	return vuln.Fixed != "" && compareVersions(version, vuln.Fixed) < 0
+}
+
+func compareVersions(a, b string) int {
+	aEpoch, a := splitEpoch(a)
+	bEpoch, b := splitEpoch(b)
+	if aEpoch != bEpoch {
+		return cmp.Compare(aEpoch, bEpoch)
+	}
+
+	as, bs := versionParts(a), versionParts(b)
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		switch {
+		case i >= len(as):
+			if bs[i].pre {
+				return 1
+			}
+			return -1
+		case i >= len(bs):
+			if as[i].pre {
+				return -1
+			}
+			return 1
+		}
+
+		x, y := as[i], bs[i]
+		if x.pre != y.pre {
+			if x.pre {
+				return -1
+			}
+			return 1
+		}
+		if x.numeric && y.numeric {
+			if c := cmp.Compare(x.number, y.number); c != 0 {
+				return c
+			}
+			continue
+		}
+		if x.numeric != y.numeric {
+			if x.numeric {
+				return 1
+			}
+			return -1
+		}
+		if c := strings.Compare(x.text, y.text); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+type versionPart struct {
+	text    string
+	number  int
+	numeric bool
+	pre     bool
+}
+
+var preReleaseSuffixes = []string{"alpha", "beta", "pre", "rc"}
+
+func splitEpoch(version string) (int, string) {
+	prefix, rest, ok := strings.Cut(version, ":")
+	if !ok {
+		return 0, version
+	}
+	epoch, err := strconv.Atoi(prefix)
+	if err != nil {
+		return 0, version
+	}
+	return epoch, rest
+}
+
+func versionParts(version string) []versionPart {
+	parts := []versionPart{}
+	separator := rune(0)
+	runes := []rune(version)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			separator = r
+			i++
+			continue
+		}
+
+		digits := unicode.IsDigit(r)
+		j := i
+		for j < len(runes) && (unicode.IsDigit(runes[j]) == digits) && (unicode.IsLetter(runes[j]) || unicode.IsDigit(runes[j])) {
+			j++
+		}
+		part := versionPart{text: string(runes[i:j]), numeric: digits}
+		if digits {
+			part.number, _ = strconv.Atoi(part.text)
+		}
+		part.pre = separator == '~' || (separator == '_' && !digits && slices.Contains(preReleaseSuffixes, part.text))
+		parts = append(parts, part)
+		separator = 0
+		i = j
+	}
+	return parts
+}
@@ -0,0 +1,117 @@
+package sbom
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.2.3", "1.2.3", 0},
+		{"1.2.3", "1.2.4", -1},
+		{"1.10.0", "1.9.0", 1},
+		{"1.2.3-r9", "1.2.3-r10", -1},
+		{"1.2.3-r10", "1.2.3-r9", 1},
+		{"3.0.15-r1", "3.0.15-r1", 0},
+		{"1.2", "1.2.1", -1},
+		{"1.2.3_rc1", "1.2.3", -1},
+		{"1.2.3_rc1", "1.2.3_rc2", -1},
+		{"1.2.3_p1", "1.2.3", 1},
+		{"1.0~rc1", "1.0", -1},
+		{"1.0~rc1-1", "1.0-1", -1},
+		{"2.36-9+deb12u4", "2.36-9+deb12u10", -1},
+		{"1:1.0", "2.0", 1},
+		{"1:1.0", "1:1.1", -1},
+		{"1.0a", "1.0b", -1},
+		{"1.0.1", "1.0a", 1},
+	}
+	for _, tt := range tests {
+		if got := compareVersions(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestAffected(t *testing.T) {
+	tests := []struct {
+		name    string
+		vuln    Vulnerability
+		version string
+		want    bool
+	}{
+		{"before fixed revision", Vulnerability{Fixed: "1.2.3-r10"}, "1.2.3-r9", true},
+		{"at fixed revision", Vulnerability{Fixed: "1.2.3-r10"}, "1.2.3-r10", false},
+		{"after fixed revision", Vulnerability{Fixed: "1.2.3-r10"}, "1.2.4-r0", false},
+		{"listed version", Vulnerability{Versions: []string{"2.0.0"}}, "2.0.0", true},
+		{"unlisted version without fix", Vulnerability{Versions: []string{"2.0.0"}}, "2.0.1", false},
+		{"older epoch", Vulnerability{Fixed: "1:1.0-1"}, "9.9-1", true},
+	}
+	for _, tt := range tests {
+		if got := affected(tt.vuln, tt.version); got != tt.want {
+			t.Errorf("%s: affected(%q) = %v, want %v", tt.name, tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestParsePackages(t *testing.T) {
+	tests := []struct {
+		name      string
+		ecosystem string
+		database  string
+		want      []Component
+	}{
+		{
+			name:      "apk",
+			ecosystem: EcosystemAPK,
+			database:  "C:Q1abc\nP:musl\nV:1.2.5-r0\nA:x86_64\n\nP:busybox\nV:1.36.1-r29\n",
+			want: []Component{
+				{Type: "library", Name: "musl", Version: "1.2.5-r0"},
+				{Type: "library", Name: "busybox", Version: "1.36.1-r29"},
+			},
+		},
+		{
+			name:      "deb",
+			ecosystem: EcosystemDeb,
+			database:  "Package: libc6\nStatus: install ok installed\nVersion: 2.36-9+deb12u4\n\nPackage: zlib1g\nVersion: 1:1.2.13.dfsg-1\n\n",
+			want: []Component{
+				{Type: "library", Name: "libc6", Version: "2.36-9+deb12u4"},
+				{Type: "library", Name: "zlib1g", Version: "1:1.2.13.dfsg-1"},
+			},
+		},
+		{
+			name:      "entry without version",
+			ecosystem: EcosystemAPK,
+			database:  "P:broken\n\nP:ok\nV:1.0\n",
+			want:      []Component{{Type: "library", Name: "ok", Version: "1.0"}},
+		},
+		{
+			name:      "empty",
+			ecosystem: EcosystemDeb,
+			database:  "",
+			want:      []Component{},
+		},
+	}
+	for _, tt := range tests {
+		if got := parsePackages(tt.ecosystem, tt.database); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: parsePackages() = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestScanEmptyDocument(t *testing.T) {
+	vulns := []Vulnerability{{ID: "CVE-1", Package: "musl", Fixed: "1.2.5-r1", Severity: "low"}}
+	doc := New("app:1")
+
+	report := Scan([]Document{doc}, vulns, "high")
+	if !report.Blocked || !slices.Equal(report.Empty, []string{"app:1"}) {
+		t.Errorf("Scan() with high threshold = %+v, want blocked empty image", report)
+	}
+
+	report = Scan([]Document{doc}, vulns, "none")
+	if report.Blocked || len(report.Empty) != 1 {
+		t.Errorf("Scan() with none threshold = %+v, want unblocked empty image", report)
+	}
+}